@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/coolbry95/magicmachine/rulegen"
+	"github.com/pkg/profile"
+)
+
+const analyzeDescription = "reverses passwords to source words and generates rules"
+
+func runAnalyze(args []string) int {
+	flags := newFlagSet("analyze", "passwords", analyzeDescription)
+	addTuningFlags(flags)
+	addDebugFlags(flags)
+	addEngineFlags(flags)
+
+	// threads
+	threads = flags.Int("threads", runtime.NumCPU(), "number of threads to use default max CPUS")
+
+	// out file basename
+	basename = flags.String("basename", "analysis", "basename for out files")
+	minCount = flags.Int("mincount", 1, "minimum times a rule or word must be seen to be in the sorted files")
+
+	// format of the password file
+	format := flags.String("format", formatPlain, "format of the password file \"plain\", \"hashcat\" or \"john\" potfile or \"userpass\"")
+	hashFields := flags.Int("hashfields", 1, "fields before the plain in a hashcat potfile, 2 for hash:salt:plain")
+
+	// stream to stdout instead of writing files
+	stdout := flags.String("stdout", "", "write \"rules\", \"words\" or \"both\" to stdout instead of files")
+
+	// machine readable record of every password
+	jsonl := flags.String("jsonl", "", "write a JSON Lines record of every password to this file, - for stdout")
+
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return flagExit(err)
+	}
+
+	if len(args) != 1 {
+		log.Println("no password file specified")
+		flags.Usage()
+		return exitUsage
+	}
+
+	if err := checkFormat(*format); err != nil {
+		log.Println(err)
+		flags.Usage()
+		return exitUsage
+	}
+
+	if *hashFields < 1 {
+		log.Println("-hashfields has to be at least 1")
+		flags.Usage()
+		return exitUsage
+	}
+
+	if *jsonl == "-" && len(*stdout) > 0 {
+		log.Println("-jsonl and -stdout cannot both write to stdout")
+		flags.Usage()
+		return exitUsage
+	}
+
+	var out output
+	if len(*stdout) > 0 {
+		out, err = newStreamOutput(os.Stdout, *stdout)
+		if err != nil {
+			log.Println(err)
+			flags.Usage()
+			return exitUsage
+		}
+	}
+
+	defer profile.Start().Stop()
+
+	passwords, err := openPasswords(args[0])
+	if err != nil {
+		log.Println(err)
+		return exitError
+	}
+	defer passwords.Close()
+
+	newSpeller, err := loadEngine()
+	if err != nil {
+		log.Println(err)
+		return exitError
+	}
+
+	if out == nil {
+		out, err = newFileOutput(*basename, opts.Verify, *minCount)
+		if err != nil {
+			log.Println(err)
+			return exitError
+		}
+	}
+
+	if len(*jsonl) > 0 {
+		jsonOut, err := newJSONOutput(*jsonl)
+		if err != nil {
+			out.close()
+			log.Println(err)
+			return exitError
+		}
+		out = multiOutput{out, jsonOut}
+	}
+
+	scanner := bufio.NewScanner(passwords)
+
+	// p is the channel to send the passwords down to get processed
+	p := make(chan string, *threads)
+	// results is the channel to send completed passwords down
+	results := make(chan result, *threads)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < *threads; i++ {
+		m, release, err := newSpeller()
+		if err != nil {
+			log.Println("engine err", err)
+			close(p)
+			wg.Wait()
+			out.close()
+			return exitError
+		}
+		g := rulegen.NewGenerator(m, opts)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer release()
+
+			for pass := range p {
+				r := result{password: pass}
+				if rulegen.Reversible([]rune(pass)) {
+					r.words = g.Analyze(pass)
+				} else {
+					r.skipped = true
+				}
+				results <- r
+			}
+		}()
+	}
+
+	var printer sync.WaitGroup
+	var printErr error
+	printer.Add(1)
+	go func() {
+		printErr = printRules(out, results)
+		printer.Done()
+	}()
+
+	quit := make(chan struct{})
+	var counter uint
+	ticker := time.Tick(time.Second * 5)
+	start := time.Now()
+
+	go func() {
+		for {
+			select {
+			case <-ticker:
+				elapsed := uint(time.Since(start).Seconds())
+				if elapsed > 0 {
+					fmt.Fprintf(os.Stderr, "\033[2Kpasswords processed %d; duration: %v; %d pass/s\r", counter, time.Since(start), counter/elapsed)
+				}
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	for scanner.Scan() {
+		counter++
+		temp, ok := parsePassword(scanner.Text(), *format, *hashFields)
+		if ok {
+			p <- temp
+		}
+	}
+
+	code := exitOK
+	if err := scanner.Err(); err != nil {
+		log.Println(err)
+		code = exitError
+	}
+
+	close(quit)
+	close(p)
+	wg.Wait()
+	close(results)
+	printer.Wait()
+	if printErr != nil {
+		log.Println("cannot write output:", printErr)
+		code = exitError
+	}
+
+	// this makes the terminal line go back to normal
+	fmt.Fprintln(os.Stderr)
+
+	return code
+}
+
+// openPasswords opens the password file or stdin when the name is "-"
+func openPasswords(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return ioutil.NopCloser(os.Stdin), nil
+	}
+
+	if info, err := os.Stat(name); err != nil {
+		return nil, fmt.Errorf("could not open file for reading: %v", err)
+	} else if info.IsDir() {
+		return nil, errors.New("Cannot use directory")
+	}
+
+	return os.Open(name)
+}
@@ -0,0 +1,253 @@
+package distance
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Costs are what each edit costs when choosing the edits turning a word into
+// a password, the cheapest edits are the ones made into rules
+// inserts before the word become ^X rules and inserts after it become $X rules
+// so they cost their own amount, the first insert of each block costs the Open
+// cost and the rest of the block costs the Extend cost
+type Costs struct {
+	Insert  int
+	Delete  int
+	Replace int
+	// Case is replacing a letter with the same letter in the other case
+	Case int
+	// Swap is swapping two characters next to each other
+	Swap int
+
+	PrefixOpen   int
+	PrefixExtend int
+	SuffixOpen   int
+	SuffixExtend int
+}
+
+// UnitCosts returns the Costs where every edit costs 1
+// the cheapest edits are the fewest, the optimal string alignment distance
+func UnitCosts() Costs {
+	return Costs{
+		Insert:       1,
+		Delete:       1,
+		Replace:      1,
+		Case:         1,
+		Swap:         1,
+		PrefixOpen:   1,
+		PrefixExtend: 1,
+		SuffixOpen:   1,
+		SuffixExtend: 1,
+	}
+}
+
+// HashcatCosts returns the Costs where every edit costs the length of the
+// hashcat rule function it becomes
+// ^X and $X are shorter than iNX so characters added at the ends are
+// inserted before or after the word instead of in the middle of it
+func HashcatCosts() Costs {
+	return Costs{
+		Insert:       3, // iNX
+		Delete:       2, // DN
+		Replace:      3, // oNX
+		Case:         2, // TN
+		Swap:         3, // *NM
+		PrefixOpen:   2, // ^X
+		PrefixExtend: 2,
+		SuffixOpen:   2, // $X
+		SuffixExtend: 2,
+	}
+}
+
+// costNames are the names used by ParseCosts and String
+var costNames = []struct {
+	name string
+	cost func(c *Costs) *int
+}{
+	{"insert", func(c *Costs) *int { return &c.Insert }},
+	{"delete", func(c *Costs) *int { return &c.Delete }},
+	{"replace", func(c *Costs) *int { return &c.Replace }},
+	{"case", func(c *Costs) *int { return &c.Case }},
+	{"swap", func(c *Costs) *int { return &c.Swap }},
+	{"prefixopen", func(c *Costs) *int { return &c.PrefixOpen }},
+	{"prefixextend", func(c *Costs) *int { return &c.PrefixExtend }},
+	{"suffixopen", func(c *Costs) *int { return &c.SuffixOpen }},
+	{"suffixextend", func(c *Costs) *int { return &c.SuffixExtend }},
+}
+
+// ParseCosts parses costs separated by commas such as "hashcat,insert=4"
+// "unit" and "hashcat" set every cost and name=value sets one of them
+// the costs start as UnitCosts
+func ParseCosts(s string) (Costs, error) {
+	c := UnitCosts()
+	for _, v := range strings.Split(s, ",") {
+		v = strings.ToLower(strings.TrimSpace(v))
+		switch v {
+		case "", "unit":
+			c = UnitCosts()
+			continue
+		case "hashcat":
+			c = HashcatCosts()
+			continue
+		}
+
+		name, value := v, ""
+		if i := strings.Index(v, "="); i >= 0 {
+			name, value = v[:i], v[i+1:]
+		}
+		cost := c.named(name)
+		if cost == nil {
+			return Costs{}, fmt.Errorf("unknown cost %q, costs are unit, hashcat or name=value", v)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return Costs{}, fmt.Errorf("cost %s: %q is not a number", name, value)
+		}
+		*cost = n
+	}
+	return c, c.Validate()
+}
+
+// named returns the cost called name or nil when there is none
+func (c *Costs) named(name string) *int {
+	for _, v := range costNames {
+		if v.name == name {
+			return v.cost(c)
+		}
+	}
+	return nil
+}
+
+// Validate checks every cost is at least 1
+// an edit costing nothing would be free to make as often as it likes
+func (c Costs) Validate() error {
+	for _, v := range costNames {
+		if n := *v.cost(&c); n < 1 {
+			return fmt.Errorf("cost %s must be at least 1, got %d", v.name, n)
+		}
+	}
+	return nil
+}
+
+func (c Costs) String() string {
+	switch c {
+	case UnitCosts():
+		return "unit"
+	case HashcatCosts():
+		return "hashcat"
+	}
+
+	names := make([]string, len(costNames))
+	for i, v := range costNames {
+		names[i] = v.name + "=" + strconv.Itoa(*v.cost(&c))
+	}
+	return strings.Join(names, ",")
+}
+
+// Set parses s into c so Costs can be a flag
+func (c *Costs) Set(s string) error {
+	v, err := ParseCosts(s)
+	if err != nil {
+		return err
+	}
+	*c = v
+	return nil
+}
+
+// Weighted returns what the cheapest edits turning a into b cost
+func Weighted(a, b []rune, costs Costs) int {
+	matrix := WeightedMatrix(a, b, costs)
+	return matrix[len(b)][len(a)]
+}
+
+// WeightedMatrix fills the matrix of Weighted distances the way
+// LevenshteinMatrix does
+// it is the OSA matrix when every cost is 1
+func WeightedMatrix(a, b []rune, costs Costs) [][]int {
+	var m Matrix
+	return m.Weighted(a, b, costs)
+}
+
+// Matrix keeps the memory of a matrix so it can be filled again for the next
+// words without allocating unless they are longer
+// a Matrix can only be used by one goroutine at a time
+type Matrix struct {
+	cells []int
+	rows  [][]int
+}
+
+// resize makes the matrix rows by cols
+func (m *Matrix) resize(rows, cols int) [][]int {
+	if cap(m.cells) < rows*cols {
+		m.cells = make([]int, rows*cols)
+	}
+	if cap(m.rows) < rows {
+		m.rows = make([][]int, rows)
+	}
+	m.rows = m.rows[:rows]
+	for i := range m.rows {
+		m.rows[i] = m.cells[i*cols : (i+1)*cols : (i+1)*cols]
+	}
+	return m.rows
+}
+
+// Weighted fills the matrix the way WeightedMatrix does
+// the matrix returned is only good until the Matrix is filled again
+func (m *Matrix) Weighted(a, b []rune, costs Costs) [][]int {
+	matrix := m.resize(len(b)+1, len(a)+1)
+	matrix[0][0] = 0
+	for i := 1; i < len(b)+1; i++ {
+		matrix[i][0] = matrix[i-1][0] + costs.InsertCost(i, 0, len(a), len(b))
+	}
+	for j := 1; j < len(a)+1; j++ {
+		matrix[0][j] = matrix[0][j-1] + costs.Delete
+	}
+
+	for i := 1; i < len(b)+1; i++ {
+		for j := 1; j < len(a)+1; j++ {
+			insertion := matrix[i-1][j] + costs.InsertCost(i, j, len(a), len(b))
+			deletion := matrix[i][j-1] + costs.Delete
+			substitution := matrix[i-1][j-1] + costs.ReplaceCost(a[j-1], b[i-1])
+			matrix[i][j] = min(insertion, min(deletion, substitution))
+
+			// ab swapped to ba
+			if IsSwap(a, b, i, j) {
+				matrix[i][j] = min(matrix[i][j], matrix[i-2][j-2]+costs.Swap)
+			}
+		}
+	}
+	return matrix
+}
+
+// InsertCost is the cost of inserting the character of b before i in the
+// column j of a matrix from a of length aLen to b of length bLen
+// every path inserting in the first or last column inserts the block of
+// characters before or after a, the block before starts at the top of the
+// matrix and the block after ends at the bottom so those inserts are charged
+// the Open cost
+func (c Costs) InsertCost(i, j, aLen, bLen int) int {
+	switch {
+	case j == 0 && i == 1:
+		return c.PrefixOpen
+	case j == 0:
+		return c.PrefixExtend
+	case j == aLen && i == bLen:
+		return c.SuffixOpen
+	case j == aLen:
+		return c.SuffixExtend
+	}
+	return c.Insert
+}
+
+// ReplaceCost is the cost of replacing a with b, nothing when they are the same
+func (c Costs) ReplaceCost(a, b rune) int {
+	switch {
+	case a == b:
+		return 0
+	case unicode.ToLower(a) == unicode.ToLower(b):
+		return c.Case
+	}
+	return c.Replace
+}
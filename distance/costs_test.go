@@ -0,0 +1,113 @@
+package distance
+
+import "testing"
+
+func TestParseCosts(t *testing.T) {
+	custom := UnitCosts()
+	custom.SuffixOpen = 3
+
+	hashcat := HashcatCosts()
+	hashcat.Insert = 4
+
+	var parse = []struct {
+		in  string
+		out Costs
+		err bool
+	}{
+		{"", UnitCosts(), false},
+		{"unit", UnitCosts(), false},
+		{"Hashcat", HashcatCosts(), false},
+		{"suffixopen=3", custom, false},
+		{"hashcat, insert=4", hashcat, false},
+		{"insert=0", Costs{}, true},
+		{"insert=a", Costs{}, true},
+		{"append=1", Costs{}, true},
+	}
+
+	for _, test := range parse {
+		out, err := ParseCosts(test.in)
+		if (err != nil) != test.err {
+			t.Errorf("%q: error should be %v, got %v", test.in, test.err, err)
+			continue
+		}
+		if !test.err && out != test.out {
+			t.Errorf("%q: should be %v, got %v", test.in, test.out, out)
+		}
+	}
+
+	if s := custom.String(); s != "insert=1,delete=1,replace=1,case=1,swap=1,prefixopen=1,prefixextend=1,suffixopen=3,suffixextend=1" {
+		t.Errorf("should list every cost, got %s", s)
+	}
+	if out, err := ParseCosts(custom.String()); err != nil || out != custom {
+		t.Errorf("should parse its own string, got %v %v", out, err)
+	}
+}
+
+func TestWeighted(t *testing.T) {
+	blocks := Costs{Insert: 9, Delete: 1, Replace: 1, Case: 1, Swap: 1, PrefixOpen: 5, PrefixExtend: 1, SuffixOpen: 5, SuffixExtend: 1}
+
+	var costs = []struct {
+		a, b  string
+		costs Costs
+		out   int
+	}{
+		{"abc", "abc", HashcatCosts(), 0},
+		{"love", "love12", HashcatCosts(), 4},
+		{"love", "12love", HashcatCosts(), 4},
+		{"love", "lo12ve", HashcatCosts(), 6},
+		{"love", "Love", HashcatCosts(), 2},
+		{"love", "Lave", HashcatCosts(), 5},
+		{"love", "olve", HashcatCosts(), 3},
+		{"café", "CAFE", HashcatCosts(), 9},
+		// the first insert of a block costs more than the rest
+		{"love", "love123", blocks, 7},
+		{"love", "1love23", blocks, 11},
+	}
+
+	for _, test := range costs {
+		if out := Weighted([]rune(test.a), []rune(test.b), test.costs); out != test.out {
+			t.Errorf("%s %s %v: should be %d, got %d", test.a, test.b, test.costs, test.out, out)
+		}
+	}
+
+	// every cost 1 is OSA
+	if out := Weighted([]rune("rules"), []rune("ralse"), UnitCosts()); out != 2 {
+		t.Errorf("should be 2, got %d", out)
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	var m Matrix
+	var words = [][2]string{
+		{"password", "passw0rd123"},
+		{"love", "lolove"},
+		{"", "abc"},
+		{"abc", ""},
+		{"password", "P@ssw0rd"},
+	}
+
+	for _, w := range words {
+		a, b := []rune(w[0]), []rune(w[1])
+		out := m.Weighted(a, b, HashcatCosts())
+		expected := WeightedMatrix(a, b, HashcatCosts())
+		if len(out) != len(expected) || len(out[0]) != len(expected[0]) {
+			t.Fatalf("%s %s: should be %d by %d, got %d by %d", w[0], w[1], len(expected), len(expected[0]), len(out), len(out[0]))
+		}
+		for i := range expected {
+			for j := range expected[i] {
+				if out[i][j] != expected[i][j] {
+					t.Errorf("%s %s: %d %d should be %d, got %d", w[0], w[1], i, j, expected[i][j], out[i][j])
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkMatrix(b *testing.B) {
+	x, y := []rune("password"), []rune("P@ssw0rd123")
+	var m Matrix
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		m.Weighted(x, y, UnitCosts())
+	}
+}
@@ -0,0 +1,125 @@
+// Package distance computes edit distances between words
+// every distance counts characters not bytes so a password with accents or
+// full width letters is as far from its word as an ASCII one
+package distance
+
+// Levenshtein returns the fewest inserts, deletes and replaces turning a into b
+func Levenshtein(a, b []rune) int {
+	a, b = trim(a, b)
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	if len(a) == 0 {
+		return len(b)
+	}
+	if len(a) <= wordSize {
+		return myers(a, b, false)
+	}
+	return rows(a, b, false)
+}
+
+// OSA returns the fewest inserts, deletes, replaces and swaps of two
+// characters next to each other turning a into b
+// it is the optimal string alignment distance, also called the restricted
+// Damerau-Levenshtein distance, so each character is only swapped once
+func OSA(a, b []rune) int {
+	a, b = trim(a, b)
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	if len(a) == 0 {
+		return len(b)
+	}
+	if len(a) <= wordSize {
+		return myers(a, b, true)
+	}
+	return rows(a, b, true)
+}
+
+// trim removes the prefix and suffix a and b have in common
+// they cost nothing so the distance is the same without them
+func trim(a, b []rune) ([]rune, []rune) {
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+	start := 0
+	for start < len(a) && start < len(b) && a[start] == b[start] {
+		start++
+	}
+	return a[start:], b[start:]
+}
+
+// rows computes the distance a row of the matrix at a time
+// it is used for words too long for myers
+func rows(a, b []rune, swaps bool) int {
+	// the row before the last is only needed for swaps
+	prev := make([]int, len(a)+1)
+	last := make([]int, len(a)+1)
+	row := make([]int, len(a)+1)
+	for j := range last {
+		last[j] = j
+	}
+
+	for i := 1; i < len(b)+1; i++ {
+		row[0] = i
+		for j := 1; j < len(a)+1; j++ {
+			cost := 1
+			if a[j-1] == b[i-1] {
+				cost = 0
+			}
+			row[j] = min(last[j]+1, min(row[j-1]+1, last[j-1]+cost))
+
+			if swaps && IsSwap(a, b, i, j) {
+				row[j] = min(row[j], prev[j-2]+1)
+			}
+		}
+		prev, last, row = last, row, prev
+	}
+	return last[len(a)]
+}
+
+// LevenshteinMatrix fills the matrix of Levenshtein distances
+// matrix[i][j] is the distance from the first j characters of a to the first
+// i characters of b
+func LevenshteinMatrix(a, b []rune) [][]int {
+	matrix := make([][]int, len(b)+1)
+	for i := 0; i < len(b)+1; i++ {
+		matrix[i] = make([]int, len(a)+1)
+		matrix[i][0] = i
+	}
+	for j := 0; j < len(a)+1; j++ {
+		matrix[0][j] = j
+	}
+
+	for i := 1; i < len(b)+1; i++ {
+		for j := 1; j < len(a)+1; j++ {
+			// if they match carry down the value from above to the left
+			if b[i-1] == a[j-1] {
+				matrix[i][j] = matrix[i-1][j-1]
+				continue
+			}
+			insertion := matrix[i-1][j] + 1
+			deletion := matrix[i][j-1] + 1
+			substitution := matrix[i-1][j-1] + 1
+			matrix[i][j] = min(insertion, min(deletion, substitution))
+		}
+	}
+	return matrix
+}
+
+// OSAMatrix fills the matrix of OSA distances the way LevenshteinMatrix does
+func OSAMatrix(a, b []rune) [][]int {
+	return WeightedMatrix(a, b, UnitCosts())
+}
+
+// IsSwap checks if the two characters of a before j are swapped in b before i
+func IsSwap(a, b []rune, i, j int) bool {
+	return i > 1 && j > 1 && b[i-1] != b[i-2] && b[i-1] == a[j-2] && b[i-2] == a[j-1]
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,122 @@
+package distance
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	var distance = []struct {
+		a, b string
+		out  int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"abc", "abc", 0},
+		{"california", "California", 1},
+		{"kitten", "sitting", 3},
+		// swaps are two edits
+		{"rules", "ralse", 3},
+		{"abc", "acb", 2},
+		// characters not bytes
+		{"café", "cafe", 1},
+		{"pass", "ｐａｓｓ", 4},
+		{"ｐａｓｓ", "ｐａｓ", 1},
+		{"Crème", "Creme1", 2},
+	}
+
+	for _, test := range distance {
+		if out := Levenshtein([]rune(test.a), []rune(test.b)); out != test.out {
+			t.Errorf("%s %s: should be %d, got %d", test.a, test.b, test.out, out)
+		}
+	}
+}
+
+func TestOSA(t *testing.T) {
+	var distance = []struct {
+		a, b string
+		out  int
+	}{
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"abc", "abc", 0},
+		{"abc", "abd", 1},
+		{"abc", "acb", 1},
+		{"password", "apssword", 1},
+		{"password", "passw0rd1", 2},
+		{"kitten", "sitting", 3},
+		{"abab", "baba", 2},
+		// each character is only swapped once
+		{"ca", "abc", 3},
+		{"rules", "ralse", 2},
+		{"café", "cafe", 1},
+		{"éa", "aé", 1},
+	}
+
+	for _, test := range distance {
+		if out := OSA([]rune(test.a), []rune(test.b)); out != test.out {
+			t.Errorf("%s %s: should be %d, got %d", test.a, test.b, test.out, out)
+		}
+		matrix := OSAMatrix([]rune(test.a), []rune(test.b))
+		if out := matrix[len(matrix)-1][len(matrix[0])-1]; out != test.out {
+			t.Errorf("%s %s: matrix should be %d, got %d", test.a, test.b, test.out, out)
+		}
+	}
+}
+
+func TestLevenshteinMatrix(t *testing.T) {
+	matrix := LevenshteinMatrix([]rune("love"), []rune("lve"))
+	if len(matrix) != 4 || len(matrix[0]) != 5 {
+		t.Fatalf("should be 4 by 5, got %d by %d", len(matrix), len(matrix[0]))
+	}
+	// l, lo, lov and love to lv
+	for j, out := range []int{2, 1, 1, 1, 2} {
+		if matrix[2][j] != out {
+			t.Errorf("%d: should be %d, got %d", j, out, matrix[2][j])
+		}
+	}
+}
+
+// words longer than myers can use are computed a row at a time
+func TestLongWords(t *testing.T) {
+	a := []rune(strings.Repeat("password", 10))
+	b := []rune(strings.Repeat("pasword", 10) + "é")
+
+	if out := Levenshtein(a, b); out != 11 {
+		t.Errorf("should be 11, got %d", out)
+	}
+	b[1], b[2] = b[2], b[1]
+	if out := OSA(a, b); out != 12 {
+		t.Errorf("should be 12, got %d", out)
+	}
+}
+
+var result int
+
+func BenchmarkLevenshtein(b *testing.B) {
+	x, y := []rune("asdfadsf"), []rune("lkjlkjhjhlkjl")
+	var r int
+	for n := 0; n < b.N; n++ {
+		r = Levenshtein(x, y)
+	}
+	result = r
+}
+
+func BenchmarkOSA(b *testing.B) {
+	x, y := []rune("asdfadsf"), []rune("lkjlkjhjhlkjl")
+	var r int
+	for n := 0; n < b.N; n++ {
+		r = OSA(x, y)
+	}
+	result = r
+}
+
+func BenchmarkLevenshteinMatrix(b *testing.B) {
+	x, y := []rune("asdfadsf"), []rune("lkjlkjhjhlkjl")
+	var r int
+	for n := 0; n < b.N; n++ {
+		r = LevenshteinMatrix(x, y)[len(y)][len(x)]
+	}
+	result = r
+}
@@ -0,0 +1,94 @@
+package distance
+
+// wordSize is the longest word myers can use, one bit for each character
+const wordSize = 64
+
+// pattern holds a bit for every position of each character of a word
+// ASCII characters are looked up in an array and the rest in a short list
+type pattern struct {
+	ascii [128]uint64
+	other []runeMask
+}
+
+type runeMask struct {
+	r    rune
+	mask uint64
+}
+
+// add sets the positions of the characters of a
+func (p *pattern) add(a []rune) {
+	for i, r := range a {
+		if r >= 0 && r < 128 {
+			p.ascii[r] |= 1 << uint(i)
+			continue
+		}
+
+		found := false
+		for k := range p.other {
+			if p.other[k].r == r {
+				p.other[k].mask |= 1 << uint(i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			p.other = append(p.other, runeMask{r, 1 << uint(i)})
+		}
+	}
+}
+
+// mask returns the positions r is at in the word
+func (p *pattern) mask(r rune) uint64 {
+	if r >= 0 && r < 128 {
+		return p.ascii[r]
+	}
+	for _, m := range p.other {
+		if m.r == r {
+			return m.mask
+		}
+	}
+	return 0
+}
+
+// myers computes the Levenshtein distance from a to b with Myers'
+// bit-parallel algorithm, a whole column of the matrix at a time
+// a can be at most wordSize characters
+// with swaps it is Hyyrö's extension computing the OSA distance
+func myers(a, b []rune, swaps bool) int {
+	var p pattern
+	p.add(a)
+	last := uint64(1) << uint(len(a)-1)
+
+	// vp and vn are the positions the column goes up or down by one
+	vp := ^uint64(0)
+	vn := uint64(0)
+	score := len(a)
+
+	// the column and matches of the character before for swaps
+	var d0, prevEq uint64
+	for _, r := range b {
+		eq := p.mask(r)
+
+		tr := uint64(0)
+		if swaps {
+			tr = ((^d0 & eq) << 1) & prevEq
+		}
+		d0 = (((eq & vp) + vp) ^ vp) | eq | vn | tr
+		hp := vn | ^(d0 | vp)
+		hn := vp & d0
+
+		if hp&last != 0 {
+			score++
+		} else if hn&last != 0 {
+			score--
+		}
+
+		// the top row goes up by one for every character of b
+		hp = hp<<1 | 1
+		hn = hn << 1
+		vp = hn | ^(d0 | hp)
+		vn = hp & d0
+		prevEq = eq
+	}
+	return score
+}
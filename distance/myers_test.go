@@ -0,0 +1,62 @@
+package distance
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// randomWord makes short words from few characters so there are many
+// repeated and swapped characters
+func randomWord(r *rand.Rand, max int) []rune {
+	characters := []rune("abcAé１")
+	word := make([]rune, r.Intn(max+1))
+	for i := range word {
+		word[i] = characters[r.Intn(len(characters))]
+	}
+	return word
+}
+
+func TestMyers(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 20000; n++ {
+		a, b := randomWord(r, 10), randomWord(r, 10)
+		if len(a) == 0 {
+			continue
+		}
+
+		lev := LevenshteinMatrix(a, b)
+		if out := myers(a, b, false); out != lev[len(b)][len(a)] {
+			t.Fatalf("%s %s: should be %d, got %d", string(a), string(b), lev[len(b)][len(a)], out)
+		}
+		if out := rows(a, b, false); out != lev[len(b)][len(a)] {
+			t.Fatalf("%s %s: rows should be %d, got %d", string(a), string(b), lev[len(b)][len(a)], out)
+		}
+
+		osa := OSAMatrix(a, b)
+		if out := myers(a, b, true); out != osa[len(b)][len(a)] {
+			t.Fatalf("%s %s: swaps should be %d, got %d", string(a), string(b), osa[len(b)][len(a)], out)
+		}
+		if out := rows(a, b, true); out != osa[len(b)][len(a)] {
+			t.Fatalf("%s %s: rows with swaps should be %d, got %d", string(a), string(b), osa[len(b)][len(a)], out)
+		}
+	}
+}
+
+// the whole word uses every bit
+func TestMyersWordSize(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for n := 0; n < 200; n++ {
+		a := randomWord(r, 0)
+		for len(a) < wordSize {
+			a = append(a, randomWord(r, wordSize-len(a))...)
+		}
+		b := randomWord(r, 80)
+
+		if out, expected := myers(a, b, false), rows(a, b, false); out != expected {
+			t.Fatalf("should be %d, got %d", expected, out)
+		}
+		if out, expected := myers(a, b, true), rows(a, b, true); out != expected {
+			t.Fatalf("swaps should be %d, got %d", expected, out)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+// Package enchant provides a binding to the enchant spell checking library.
+// It uses cgo and is only compiled with the enchant build tag.
+package enchant
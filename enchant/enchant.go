@@ -1,4 +1,6 @@
-// Package enchant provides a binding to the enchant spell checking library.
+//go:build enchant
+// +build enchant
+
 package enchant
 
 /*
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/coolbry95/magicmachine/spell"
+)
+
+// the engine used when -engine is not given
+const defaultEngine = "symspell"
+
+// engines holds the spell checkers that can be chosen with -engine
+// engines that need cgo register themselves when they are compiled in
+var engines = map[string]func() (spellerFactory, error){
+	"symspell": loadSymSpell,
+	// special is the old name for symspell
+	"special": loadSymSpell,
+}
+
+// engineNames returns the names of the compiled in engines
+func engineNames() []string {
+	var names []string
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// addEngineFlags adds the flags choosing and configuring the spell checker
+func addEngineFlags(flags *flag.FlagSet) {
+	// engine to use
+	engine = flags.String("engine", defaultEngine, "engine to use one of "+strings.Join(engineNames(), ", "))
+
+	// use already processed dictionary with symspell engine
+	processed = flags.String("processed", "", "processed dictionary to use")
+
+	// dictionary to use with symspell engine
+	specialDict = flags.String("specialdict", "", "dictionary to use with symspell engine")
+}
+
+// spellerFactory makes a spell checker for a single worker
+// the returned function releases the spell checker when the worker is done
+type spellerFactory func() (spell.Speller, func(), error)
+
+// loadEngine loads the engine chosen with -engine
+func loadEngine() (spellerFactory, error) {
+	load, ok := engines[*engine]
+	if !ok {
+		return nil, fmt.Errorf("unknown engine %q, available engines are %s", *engine, strings.Join(engineNames(), ", "))
+	}
+	return load()
+}
+
+func loadSymSpell() (spellerFactory, error) {
+	// the model is safe to share between the workers
+	m, err := loadModel()
+	if err != nil {
+		return nil, err
+	}
+	return func() (spell.Speller, func(), error) {
+		return m, func() {}, nil
+	}, nil
+}
+
+// dictEnv is the environment variable naming the default dictionary
+const dictEnv = "MAGICMACHINE_DICT"
+
+// bundledDicts are looked for next to the magicmachine binary
+var bundledDicts = []string{
+	"magicmachine.processed",
+	"dictionary.txt",
+}
+
+// systemDicts are the dictionaries found on most unix systems
+var systemDicts = []string{
+	"/usr/share/dict/words",
+	"/usr/dict/words",
+}
+
+// loadModel loads the dictionary for the symspell engine
+// without -processed or -specialdict the first default dictionary found is used
+func loadModel() (*spell.Model, error) {
+	if len(*processed) > 0 {
+		return loadProcessed(*processed)
+	} else if len(*specialDict) > 0 {
+		return loadDict(*specialDict)
+	}
+
+	name, err := findDict()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("using dictionary", name)
+	if strings.HasSuffix(name, ".processed") {
+		return loadProcessed(name)
+	}
+	return loadDict(name)
+}
+
+// findDict looks for a default dictionary
+func findDict() (string, error) {
+	var dicts []string
+	if name := os.Getenv(dictEnv); len(name) > 0 {
+		dicts = append(dicts, name)
+	}
+	if exe, err := os.Executable(); err == nil {
+		for _, name := range bundledDicts {
+			dicts = append(dicts, filepath.Join(filepath.Dir(exe), name))
+		}
+	}
+	dicts = append(dicts, systemDicts...)
+
+	for _, name := range dicts {
+		if info, err := os.Stat(name); err == nil && !info.IsDir() {
+			return name, nil
+		}
+	}
+
+	return "", errors.New("no dictionary provided use -specialdict, -processed or set " + dictEnv)
+}
+
+// loadProcessed opens a processed dictionary
+// the file is memory mapped so the workers share it
+func loadProcessed(name string) (*spell.Model, error) {
+	m, err := spell.OpenModel(name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return m, nil
+}
+
+// formats of the dictionaries that can be loaded
+const (
+	// a word on each line
+	dictWordlist = "wordlist"
+	// free text that is split into words and counted
+	dictText = "text"
+	// a word and its count on each line
+	dictFrequency = "frequency"
+)
+
+var dictFormats = []string{dictWordlist, dictText, dictFrequency}
+
+// checkDictFormat makes sure the dictionary format is one that is known
+func checkDictFormat(format string) error {
+	for _, f := range dictFormats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown dictionary format %q, formats are %s", format, strings.Join(dictFormats, ", "))
+}
+
+// loadDict loads a wordlist or a Hunspell dictionary
+// the words are normalized the same as the passwords
+func loadDict(name string) (*spell.Model, error) {
+	options := spell.DefaultOptions()
+	options.Normalize = opts.Normalize
+	m := spell.NewModelWithOptions(options)
+	if err := addDict(m, name, dictWordlist); err != nil {
+		return nil, err
+	}
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return m, nil
+}
+
+// addDict adds the words of a dictionary in format to the model
+// Hunspell dictionaries end in .dic and need the .aff file next to them
+func addDict(m *spell.Model, name, format string) error {
+	if strings.HasSuffix(name, ".dic") {
+		return addHunspell(m, name, strings.TrimSuffix(name, ".dic")+".aff")
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	switch format {
+	case dictText:
+		err = m.Train(file)
+	case dictFrequency:
+		err = m.LoadFrequencyList(file)
+	default:
+		err = m.LoadWordList(file)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	return nil
+}
+
+func addHunspell(m *spell.Model, dicName, affName string) error {
+	dic, err := os.Open(dicName)
+	if err != nil {
+		return err
+	}
+	defer dic.Close()
+
+	aff, err := os.Open(affName)
+	if err != nil {
+		return err
+	}
+	defer aff.Close()
+
+	if err := m.LoadHunspell(dic, aff); err != nil {
+		return fmt.Errorf("%s: %v", dicName, err)
+	}
+	return nil
+}
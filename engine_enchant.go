@@ -0,0 +1,27 @@
+//go:build enchant
+// +build enchant
+
+package main
+
+import (
+	"github.com/coolbry95/magicmachine/enchant"
+	"github.com/coolbry95/magicmachine/spell"
+)
+
+func init() {
+	engines["enchant"] = loadEnchant
+}
+
+func loadEnchant() (spellerFactory, error) {
+	return func() (spell.Speller, func(), error) {
+		m, err := enchant.NewEnchant()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		m.BrokerOrdering("*", "aspell,mysell")
+		m.LoadDict("en")
+
+		return m, m.Delete, nil
+	}, nil
+}
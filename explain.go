@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/coolbry95/magicmachine/rulegen"
+)
+
+const explainDescription = "shows how passwords are reversed to source words and rules"
+
+func runExplain(args []string) int {
+	flags := newFlagSet("explain", "passwords...", explainDescription)
+	addTuningFlags(flags)
+	addDebugFlags(flags)
+	addEngineFlags(flags)
+
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return flagExit(err)
+	}
+
+	if len(args) == 0 {
+		log.Println("no passwords specified")
+		flags.Usage()
+		return exitUsage
+	}
+
+	newSpeller, err := loadEngine()
+	if err != nil {
+		log.Println(err)
+		return exitError
+	}
+
+	m, release, err := newSpeller()
+	if err != nil {
+		log.Println("engine err", err)
+		return exitError
+	}
+	defer release()
+
+	g := rulegen.NewGenerator(m, opts)
+	for _, password := range args {
+		explainPassword(os.Stdout, decodeHex(password), g)
+	}
+
+	return exitOK
+}
+
+// explainPassword writes every step of analyzing a password
+func explainPassword(w io.Writer, password string, g *rulegen.Generator) {
+	fmt.Fprintf(w, "password: %s\n", password)
+
+	if !rulegen.Reversible([]rune(password)) {
+		fmt.Fprintf(w, "  skipped: not likely to be reversible\n\n")
+		return
+	}
+
+	words := g.GenerateWords(password)
+	if len(words) == 0 {
+		fmt.Fprintf(w, "  no words found\n\n")
+		return
+	}
+
+	for _, word := range words {
+		fmt.Fprintf(w, "  word: %s\n", word.Suggestion)
+		fmt.Fprintf(w, "    pre rule: %s\n", word.PreRule)
+		fmt.Fprintf(w, "    analyzed password: %s\n", word.Password)
+		fmt.Fprintf(w, "    distance: %d\n", word.Distance)
+
+		// the spaces of segmented words are purged before the edits
+		joined := strings.Replace(word.Suggestion, " ", "", -1)
+		paths := g.Paths(joined, word.Password)
+		for path, ok := paths.Next(); ok; path, ok = paths.Next() {
+			edits := make([]string, len(path))
+			for i, op := range path {
+				edits[i] = op.String()
+			}
+			fmt.Fprintf(w, "    edits: %s\n", strings.Join(edits, ", "))
+		}
+
+		hashcatRules := g.GenerateHashcatRules(word.Suggestion, word.Password, word.PreRule)
+
+		var failed rulegen.Rules
+		if g.Options().Verify {
+			hashcatRules, failed = g.VerifyRules(word.Suggestion, password, hashcatRules)
+		}
+
+		for _, r := range hashcatRules {
+			fmt.Fprintf(w, "    rule: %s\n", rulegen.RuleLine(r))
+		}
+		for _, r := range failed {
+			fmt.Fprintf(w, "    failed rule: %s\n", rulegen.RuleLine(r))
+		}
+	}
+	fmt.Fprintln(w)
+}
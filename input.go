@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// formats of the password file
+const (
+	formatPlain    = "plain"
+	formatHashcat  = "hashcat"
+	formatJohn     = "john"
+	formatUserPass = "userpass"
+)
+
+// checkFormat makes sure the password file format is known
+func checkFormat(format string) error {
+	switch format {
+	case formatPlain, formatHashcat, formatJohn, formatUserPass:
+		return nil
+	}
+	return fmt.Errorf("unknown format %q should be %s, %s, %s or %s",
+		format, formatPlain, formatHashcat, formatJohn, formatUserPass)
+}
+
+// parsePassword pulls the plaintext password out of a line of the password file
+// hashFields is how many fields come before the plain in a hashcat potfile,
+// 1 for hash:plain and 2 for hash:salt:plain
+// false is returned when the line does not hold a password
+func parsePassword(line, format string, hashFields int) (string, bool) {
+	i := -1
+	switch format {
+	case formatHashcat:
+		// the plain is only $HEX[] encoded with a : when hashcat was told to
+		// so the plain starts after the hash fields and can contain a :
+		for n := 0; n < hashFields; n++ {
+			next := strings.Index(line[i+1:], ":")
+			if next < 0 {
+				return "", false
+			}
+			i += next + 1
+		}
+	case formatJohn, formatUserPass:
+		// hash:plain or username:plain the plain can contain a :
+		i = strings.Index(line, ":")
+	default:
+		return decodeHex(line), true
+	}
+
+	if i < 0 {
+		return "", false
+	}
+
+	return decodeHex(line[i+1:]), true
+}
+
+const (
+	hexPrefix = "$HEX["
+	hexSuffix = "]"
+)
+
+// decodeHex decodes a password in the $HEX[70617373] format
+// anything that is not valid $HEX[] is returned as is
+func decodeHex(password string) string {
+	if !strings.HasPrefix(password, hexPrefix) || !strings.HasSuffix(password, hexSuffix) {
+		return password
+	}
+
+	decoded, err := hex.DecodeString(password[len(hexPrefix) : len(password)-len(hexSuffix)])
+	if err != nil {
+		return password
+	}
+
+	return string(decoded)
+}
+
+// encodeHex encodes a word in the $HEX[] format when it has characters that
+// cannot be written to a wordlist as is
+func encodeHex(word string) string {
+	if strings.HasPrefix(word, hexPrefix) {
+		return hexPrefix + hex.EncodeToString([]byte(word)) + hexSuffix
+	}
+
+	for _, r := range word {
+		if r == unicode.ReplacementChar || !unicode.IsPrint(r) {
+			return hexPrefix + hex.EncodeToString([]byte(word)) + hexSuffix
+		}
+	}
+
+	return word
+}
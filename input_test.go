@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParsePassword(t *testing.T) {
+	var lines = []struct {
+		line     string
+		format   string
+		fields   int
+		password string
+		ok       bool
+	}{
+		{"password1", formatPlain, 1, "password1", true},
+		{"pass:word", formatPlain, 1, "pass:word", true},
+		{"$HEX[70617373776f7264]", formatPlain, 1, "password", true},
+		{"5f4dcc3b5aa765d61d8327deb882cf99:password", formatHashcat, 1, "password", true},
+		// plains with a : when hashcat did not $HEX[] encode them
+		{"5f4dcc3b5aa765d61d8327deb882cf99:pass:word", formatHashcat, 1, "pass:word", true},
+		{"hash:salt:password", formatHashcat, 2, "password", true},
+		{"hash:salt:pass:word", formatHashcat, 2, "pass:word", true},
+		{"hash:$HEX[706173733a776f7264]", formatHashcat, 1, "pass:word", true},
+		{"hash:password", formatHashcat, 2, "", false},
+		{"$dynamic_0$5f4dcc3b5aa765d61d8327deb882cf99:pass:word", formatJohn, 1, "pass:word", true},
+		{"admin:pass:word", formatUserPass, 1, "pass:word", true},
+		{"no separator", formatHashcat, 1, "", false},
+	}
+
+	for _, l := range lines {
+		password, ok := parsePassword(l.line, l.format, l.fields)
+		if password != l.password || ok != l.ok {
+			t.Errorf("%s: should be %q %v, got %q %v", l.line, l.password, l.ok, password, ok)
+		}
+	}
+}
+
+func TestDecodeHex(t *testing.T) {
+	var passwords = []struct {
+		in  string
+		out string
+	}{
+		{"$HEX[70617373]", "pass"},
+		{"$HEX[]", ""},
+		{"$HEX[zz]", "$HEX[zz]"},
+		{"$HEX[7061", "$HEX[7061"},
+		{"pass", "pass"},
+	}
+
+	for _, p := range passwords {
+		if out := decodeHex(p.in); out != p.out {
+			t.Errorf("%s: should be %q, got %q", p.in, p.out, out)
+		}
+	}
+}
+
+func TestEncodeHex(t *testing.T) {
+	var words = []struct {
+		in  string
+		out string
+	}{
+		{"password", "password"},
+		{"pässword", "pässword"},
+		{"pass\tword", "$HEX[7061737309776f7264]"},
+		{"pass\xffword", "$HEX[70617373ff776f7264]"},
+		{"$HEX[70]", "$HEX[244845585b37305d]"},
+	}
+
+	for _, w := range words {
+		if out := encodeHex(w.in); out != w.out {
+			t.Errorf("%q: should be %q, got %q", w.in, w.out, out)
+		}
+		if out := decodeHex(encodeHex(w.in)); out != w.in {
+			t.Errorf("%q: does not round trip, got %q", w.in, out)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"log"
+	"runtime"
+
+	"github.com/coolbry95/magicmachine/spell"
+)
+
+const buildModelDescription = "processes a dictionary for the symspell engine to save time later"
+
+func runBuildModel(args []string) int {
+	flags := newFlagSet("build-model", "dictionaries...", buildModelDescription)
+	out := flags.String("out", "", "where to save the processed dictionary")
+	format := flags.String("format", dictWordlist, "format of the dictionaries \"wordlist\", \"text\" to learn words and counts from a corpus or \"frequency\" for word count lines")
+	threshold := flags.Int("threshold", 1, "times a word must be seen to be a word")
+	defaults := spell.DefaultOptions()
+	maxEditDistance := flags.Int("maxeditdistance", defaults.MaxEditDistance, "furthest a password can be from a word and still find it, more makes many more deletes")
+	prefixLength := flags.Int("prefixlength", defaults.PrefixLength, "only make deletes of this many characters from the start of each word, 0 for the whole word")
+	var normalize spell.Normalization
+	flags.Var(&normalize, "normalize", "normalize the words and the passwords looked up, a `list` of nfc or nfkc, fold and strip separated by commas")
+
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return flagExit(err)
+	}
+
+	if len(args) == 0 {
+		log.Println("no dictionary specified")
+		flags.Usage()
+		return exitUsage
+	}
+	if len(*out) == 0 {
+		log.Println("Please specify out file")
+		flags.Usage()
+		return exitUsage
+	}
+	if err := checkDictFormat(*format); err != nil {
+		log.Println(err)
+		flags.Usage()
+		return exitUsage
+	}
+	if *threshold < 1 {
+		log.Println("threshold must be at least 1")
+		flags.Usage()
+		return exitUsage
+	}
+
+	if *maxEditDistance < 1 || *maxEditDistance > spell.SuggestDistance {
+		log.Printf("maxeditdistance must be from 1 to %d", spell.SuggestDistance)
+		flags.Usage()
+		return exitUsage
+	}
+	if *prefixLength < 0 || (*prefixLength > 0 && *prefixLength <= *maxEditDistance) {
+		log.Println("prefixlength must be 0 or more than maxeditdistance")
+		flags.Usage()
+		return exitUsage
+	}
+
+	// every dictionary goes in the same model
+	m := spell.NewModelWithOptions(spell.Options{
+		MaxEditDistance: *maxEditDistance,
+		PrefixLength:    *prefixLength,
+		Normalize:       normalize,
+	})
+	m.Threshold = *threshold
+	for _, name := range args {
+		if err := addDict(m, name, *format); err != nil {
+			log.Println(err)
+			return exitError
+		}
+	}
+	if err := m.Validate(); err != nil {
+		log.Println(err)
+		return exitError
+	}
+	logModelSize(m)
+
+	if err := saveModel(m, *out); err != nil {
+		log.Println(err)
+		return exitError
+	}
+
+	return exitOK
+}
+
+// logModelSize logs how big the model is so machines can be sized for it
+func logModelSize(m *spell.Model) {
+	stats := m.Stats()
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	log.Printf("%d words %d deletes %d entries %d suggestions\n", stats.Words, stats.Deletes, stats.Entries, stats.Suggestions)
+	log.Printf("model about %d MiB, heap %d MiB, from the OS %d MiB\n", stats.Bytes>>20, mem.HeapAlloc>>20, mem.Sys>>20)
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/coolbry95/magicmachine/rulegen"
+)
+
+// result is the analysis of a single password
+type result struct {
+	password string
+	// the password was not likely to be reversible so it was not analyzed
+	skipped bool
+	words   []rulegen.Word
+}
+
+// output is where the words and rules of an analysis are written
+type output interface {
+	write(r result)
+	close() error
+}
+
+// printRules writes every analyzed password to out
+// it returns the error of closing out which has the error of any failed write
+func printRules(out output, results chan result) error {
+	for r := range results {
+		out.write(r)
+	}
+
+	return out.close()
+}
+
+// fileOutput writes the words and rules to files named after basename
+type fileOutput struct {
+	basename string
+	files    []*os.File
+
+	wordbuf   *bufio.Writer
+	rulebuf   *bufio.Writer
+	failedbuf *bufio.Writer
+
+	stats *statistics
+}
+
+func newFileOutput(basename string, verify bool, minCount int) (*fileOutput, error) {
+	out := &fileOutput{
+		basename: basename,
+		stats:    newStatistics(minCount),
+	}
+
+	var err error
+	if out.wordbuf, err = out.create(basename + ".word"); err != nil {
+		return nil, err
+	}
+	if out.rulebuf, err = out.create(basename + ".rule"); err != nil {
+		return nil, err
+	}
+	// rules that did not survive verification
+	if verify {
+		if out.failedbuf, err = out.create(basename + ".failed"); err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+// create makes a new file that is closed along with the output
+func (out *fileOutput) create(fileName string) (*bufio.Writer, error) {
+	file, err := os.Create(fileName)
+	if err != nil {
+		out.close()
+		return nil, fmt.Errorf("cannot open file to write to: %v", err)
+	}
+	out.files = append(out.files, file)
+	return bufio.NewWriter(file), nil
+}
+
+// TODO
+// make this faster right now it is slow due to using fmt.Printf and
+// concatenating strings in the String() method
+
+func (out *fileOutput) write(r result) {
+	if r.skipped {
+		return
+	}
+
+	out.stats.add(r.words)
+	for _, a := range r.words {
+		fmt.Fprintln(out.wordbuf, encodeHex(a.Suggestion))
+		fmt.Fprintf(out.rulebuf, "%v", a.Rules)
+		if out.failedbuf != nil {
+			writeFailed(out.failedbuf, a)
+		}
+	}
+}
+
+func (out *fileOutput) close() error {
+	var err error
+	// make sure that everything is flushed
+	for _, buf := range []*bufio.Writer{out.wordbuf, out.rulebuf, out.failedbuf} {
+		if buf != nil {
+			if e := buf.Flush(); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	for _, file := range out.files {
+		if e := file.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	out.files = nil
+
+	if err != nil {
+		return err
+	}
+
+	return out.stats.write(out.basename)
+}
+
+// writeFailed writes the rules dropped by verification as
+// password, word and rule separated by tabs
+func writeFailed(w io.Writer, word rulegen.Word) {
+	for _, failed := range word.FailedRules {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", encodeHex(word.Original), encodeHex(word.Suggestion), rulegen.RuleLine(failed))
+	}
+}
+
+// what is streamed by streamOutput
+const (
+	streamRules = "rules"
+	streamWords = "words"
+	streamBoth  = "both"
+)
+
+// streamOutput streams rules and/or words to a writer such as stdout
+// rules dropped by verification are logged instead
+type streamOutput struct {
+	buf   *bufio.Writer
+	words bool
+	rules bool
+}
+
+func newStreamOutput(w io.Writer, mode string) (*streamOutput, error) {
+	out := &streamOutput{buf: bufio.NewWriter(w)}
+
+	switch mode {
+	case streamRules:
+		out.rules = true
+	case streamWords:
+		out.words = true
+	case streamBoth:
+		out.rules = true
+		out.words = true
+	default:
+		return nil, fmt.Errorf("unknown output %q should be %s, %s or %s", mode, streamRules, streamWords, streamBoth)
+	}
+
+	return out, nil
+}
+
+func (out *streamOutput) write(r result) {
+	for _, a := range r.words {
+		for _, r := range a.Rules {
+			switch {
+			case out.words && out.rules:
+				fmt.Fprintf(out.buf, "%s\t%s\n", encodeHex(a.Suggestion), rulegen.RuleLine(r))
+			case out.rules:
+				fmt.Fprintln(out.buf, rulegen.RuleLine(r))
+			}
+		}
+		if out.words && !out.rules {
+			fmt.Fprintln(out.buf, encodeHex(a.Suggestion))
+		}
+		if len(a.FailedRules) > 0 {
+			writeFailed(logWriter{}, a)
+		}
+	}
+}
+
+func (out *streamOutput) close() error {
+	return out.buf.Flush()
+}
+
+// logWriter sends each write to the standard logger
+type logWriter struct{}
+
+func (logWriter) Write(p []byte) (int, error) {
+	log.Print("verification failed: ", string(p))
+	return len(p), nil
+}
+
+// jsonOutput writes a JSON record of every password on its own line
+type jsonOutput struct {
+	file *os.File
+	buf  *bufio.Writer
+	enc  *json.Encoder
+}
+
+// jsonRecord is the JSON Lines record of a single password
+type jsonRecord struct {
+	Password string         `json:"password"`
+	Skipped  bool           `json:"skipped,omitempty"`
+	Words    []rulegen.Word `json:"words"`
+}
+
+// newJSONOutput writes to fileName or stdout when fileName is "-"
+func newJSONOutput(fileName string) (*jsonOutput, error) {
+	if fileName == "-" {
+		return newJSONWriter(os.Stdout), nil
+	}
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open file to write to: %v", err)
+	}
+	out := newJSONWriter(file)
+	out.file = file
+
+	return out, nil
+}
+
+// newJSONWriter writes the records to w
+func newJSONWriter(w io.Writer) *jsonOutput {
+	out := &jsonOutput{buf: bufio.NewWriter(w)}
+	out.enc = json.NewEncoder(out.buf)
+	return out
+}
+
+func (out *jsonOutput) write(r result) {
+	record := jsonRecord{
+		Password: r.password,
+		Skipped:  r.skipped,
+		Words:    r.words,
+	}
+	if record.Words == nil {
+		record.Words = []rulegen.Word{}
+	}
+
+	// Encode adds the newline after each record
+	if err := out.enc.Encode(record); err != nil {
+		log.Println("cannot write JSON record:", err)
+	}
+}
+
+func (out *jsonOutput) close() error {
+	err := out.buf.Flush()
+	if out.file != nil {
+		if e := out.file.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// multiOutput writes to every output
+type multiOutput []output
+
+func (outs multiOutput) write(r result) {
+	for _, out := range outs {
+		out.write(r)
+	}
+}
+
+func (outs multiOutput) close() error {
+	var err error
+	for _, out := range outs {
+		if e := out.close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/coolbry95/magicmachine/rulegen"
+)
+
+// outputResults are analyzed, skipped and failed passwords
+var outputResults = []result{
+	{
+		password: "Password1",
+		words: []rulegen.Word{{
+			Distance:   2,
+			Suggestion: "password",
+			Password:   "Password1",
+			Original:   "Password1",
+			PreRule:    ":",
+			Rules:      rulegen.Rules{{"c", "$1"}},
+		}},
+	},
+	{password: "123456", skipped: true},
+	{
+		password: "1drow",
+		words: []rulegen.Word{{
+			Distance:    1,
+			Suggestion:  "word",
+			Password:    "word1",
+			Original:    "1drow",
+			PreRule:     "r",
+			Rules:       rulegen.Rules{{"$1", "r"}, {"^1"}},
+			FailedRules: rulegen.Rules{{"i01"}},
+		}},
+	},
+}
+
+func TestJSONOutput(t *testing.T) {
+	var b bytes.Buffer
+	out := newJSONWriter(&b)
+	for _, r := range outputResults {
+		out.write(r)
+	}
+	if err := out.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"password":"Password1","words":[{"distance":2,"suggestion":"password","analyzed_password":"Password1","pre_rule":":","rules":[["c","$1"]]}]}
+{"password":"123456","skipped":true,"words":[]}
+{"password":"1drow","words":[{"distance":1,"suggestion":"word","analyzed_password":"word1","pre_rule":"r","rules":[["$1","r"],["^1"]],"failed_rules":[["i01"]]}]}
+`
+	if b.String() != want {
+		t.Errorf("should be\n%s\ngot\n%s", want, b.String())
+	}
+}
+
+func TestStreamOutput(t *testing.T) {
+	var modes = []struct {
+		mode string
+		out  string
+	}{
+		{streamRules, "c $1\n$1 r\n^1\n"},
+		{streamWords, "password\nword\n"},
+		{streamBoth, "password\tc $1\nword\t$1 r\nword\t^1\n"},
+	}
+
+	for _, m := range modes {
+		var b bytes.Buffer
+		out, err := newStreamOutput(&b, m.mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range outputResults {
+			out.write(r)
+		}
+		if err := out.close(); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != m.out {
+			t.Errorf("%s: should be %q, got %q", m.mode, m.out, b.String())
+		}
+	}
+
+	if _, err := newStreamOutput(&bytes.Buffer{}, "all"); err == nil {
+		t.Errorf("all: should be an unknown output")
+	}
+}
+
+func TestWriteFailed(t *testing.T) {
+	var b bytes.Buffer
+	writeFailed(&b, outputResults[2].words[0])
+	if want := "1drow\tword\ti01\n"; b.String() != want {
+		t.Errorf("should be %q, got %q", want, b.String())
+	}
+}
+
+// failWriter fails every write like a full disk or a closed pipe
+type failWriter struct{}
+
+func (failWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("no space left on device")
+}
+
+func TestPrintRulesError(t *testing.T) {
+	stream, err := newStreamOutput(failWriter{}, streamBoth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, out := range []output{stream, newJSONWriter(failWriter{})} {
+		results := make(chan result, len(outputResults))
+		for _, r := range outputResults {
+			results <- r
+		}
+		close(results)
+
+		if err := printRules(out, results); err == nil {
+			t.Errorf("%T: the failed write should be returned", out)
+		}
+	}
+}
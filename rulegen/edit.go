@@ -0,0 +1,315 @@
+package rulegen
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/coolbry95/magicmachine/distance"
+)
+
+// PrettyPrint prints the matrix in a pretty format
+func PrettyPrint(matrix [][]int, a, b string) {
+
+	fmt.Printf("      ")
+	for _, v := range a {
+		fmt.Printf("%c  ", v)
+	}
+	fmt.Println()
+	password := []rune(b)
+	for i := range matrix {
+		if i == 0 {
+			fmt.Printf("   ")
+		} else {
+			fmt.Printf("%c  ", password[i-1])
+		}
+		for j := 0; j < len(matrix[0]); j++ {
+			fmt.Printf("%d  ", matrix[i][j])
+		}
+		fmt.Println()
+	}
+}
+
+// GenerateLevenshteinRules generates a list of paths to take based on the matrix
+// every path is built so use Paths for long passwords
+func GenerateLevenshteinRules(word, password []rune) [][]EditOp {
+	return Paths(word, password, 0).All()
+}
+
+// Paths returns an iterator over the cheapest edit paths turning word into
+// password, at most maxPaths of them or every one when maxPaths is 0
+// two characters next to each other swapped are a single swap edit
+func Paths(word, password []rune, maxPaths int) *PathIterator {
+	return WeightedPaths(word, password, distance.UnitCosts(), maxPaths)
+}
+
+// WeightedPaths is Paths where the cheapest paths are the ones costing the
+// least with costs
+func WeightedPaths(word, password []rune, costs distance.Costs, maxPaths int) *PathIterator {
+	it := &PathIterator{}
+	it.walk(distance.WeightedMatrix(word, password, costs), word, password, costs, maxPaths)
+	return it
+}
+
+// Op is an edit operation
+type Op uint8
+
+// the edit operations, the zero Op is no edit
+const (
+	Insert Op = iota + 1
+	Delete
+	Replace
+	Swap
+)
+
+var opNames = [...]string{
+	Insert:  "insert",
+	Delete:  "delete",
+	Replace: "replace",
+	Swap:    "swap",
+}
+
+func (o Op) String() string {
+	if int(o) < len(opNames) && opNames[o] != "" {
+		return opNames[o]
+	}
+	return "op(" + strconv.Itoa(int(o)) + ")"
+}
+
+// EditOp holds the edit operation and the position on the word and password it
+// occured
+// a swap is of the character at P and the one after it
+type EditOp struct {
+	Op   Op  // short for operation, insert, delete, replace or swap
+	P    int // short for password holds where the change is in the password
+	Word int // holds where the change is in the word
+}
+
+// String returns the operation and the position in the password
+func (e EditOp) String() string {
+	return e.Op.String() + " " + strconv.Itoa(e.P)
+}
+
+// these define max in sizes
+const maxuint = ^uint(0)
+const maxint = int(maxuint >> 1)
+
+// ReverseRecurse walks a matrix backwards from i, j and returns every path
+// it does not know the words so it never swaps
+// pathLen edits have already been made before i, j
+// the number of paths can grow exponentially so use a PathIterator instead
+func ReverseRecurse(matrix [][]int, i, j, pathLen int) [][]EditOp {
+	return newPathIterator(matrix, i, j, pathLen, 0).All()
+}
+
+// PathIterator walks an edit matrix backwards yielding one path at a time
+// paths are made as they are asked for so only the paths used cost anything
+// paths that are the same edits are only yielded once
+// the memory of a PathIterator is kept when it walks the next matrix
+type PathIterator struct {
+	matrix [][]int
+	// the words the matrix is for, swaps are only made when they are known
+	word, password []rune
+	// costs the matrix was made with
+	costs distance.Costs
+	// budget is the most a path can cost
+	budget int
+	// maxPaths is how many paths are yielded, 0 for every one
+	maxPaths int
+	yielded  int
+
+	// stack of walks still to finish
+	stack []pathStep
+	// ops of every walk, each walk shares the ops it was walked from
+	ops []opNode
+	// seen holds the edits of the paths already yielded
+	seen map[string]struct{}
+	// path and key are the last path yielded and its edits
+	path []EditOp
+	key  []byte
+}
+
+// pathStep is a walk through the matrix that got to i, j
+// ops is the last edit made from the end of the matrix backwards, -1 for none
+// and spent is what the edits cost
+type pathStep struct {
+	i, j  int
+	spent int
+	ops   int
+}
+
+// opNode is an edit and the index of the edit made before it while walking
+// back, so the edits of a walk are in order from the start of the word
+type opNode struct {
+	op   EditOp
+	next int
+}
+
+// newPathIterator walks matrix from i, j after edits costing spent were made
+// a path costs at most what the end of the matrix costs
+func newPathIterator(matrix [][]int, i, j, spent, maxPaths int) *PathIterator {
+	it := &PathIterator{}
+	it.reset(matrix, nil, nil, distance.UnitCosts(), maxPaths)
+	it.stack = append(it.stack, pathStep{i, j, spent, -1})
+	return it
+}
+
+// walk starts walking a matrix for word and password from the end
+func (it *PathIterator) walk(matrix [][]int, word, password []rune, costs distance.Costs, maxPaths int) {
+	it.reset(matrix, word, password, costs, maxPaths)
+	it.stack = append(it.stack, pathStep{len(matrix) - 1, len(matrix[0]) - 1, 0, -1})
+}
+
+// reset empties the iterator keeping its memory
+func (it *PathIterator) reset(matrix [][]int, word, password []rune, costs distance.Costs, maxPaths int) {
+	it.matrix = matrix
+	it.word, it.password = word, password
+	it.costs = costs
+	it.budget = matrix[len(matrix)-1][len(matrix[0])-1]
+	it.maxPaths = maxPaths
+	it.yielded = 0
+	it.stack = it.stack[:0]
+	it.ops = it.ops[:0]
+	if it.seen == nil {
+		it.seen = make(map[string]struct{})
+	}
+	for key := range it.seen {
+		delete(it.seen, key)
+	}
+}
+
+// Next returns the next path and false when there are no more paths
+// the edits are in order from the start of the word
+// the path is only good until Next is called again
+func (it *PathIterator) Next() ([]EditOp, bool) {
+	for len(it.stack) > 0 && (it.maxPaths == 0 || it.yielded < it.maxPaths) {
+		step := it.stack[len(it.stack)-1]
+		it.stack = it.stack[:len(it.stack)-1]
+
+		// the start of the matrix is the end of the walk
+		if step.i == 0 && step.j == 0 {
+			it.path = it.path[:0]
+			for k := step.ops; k >= 0; k = it.ops[k].next {
+				it.path = append(it.path, it.ops[k].op)
+			}
+
+			// looking up the key as a string does not allocate but keeping
+			// the key of a new path does
+			it.key = appendPathKey(it.key[:0], it.path)
+			if _, ok := it.seen[string(it.key)]; ok {
+				continue
+			}
+			it.seen[string(it.key)] = struct{}{}
+			it.yielded++
+			return it.path, true
+		}
+
+		it.push(step)
+	}
+	return nil, false
+}
+
+// All returns every path left
+func (it *PathIterator) All() [][]EditOp {
+	var paths [][]EditOp
+	for path, ok := it.Next(); ok; path, ok = it.Next() {
+		paths = append(paths, append([]EditOp(nil), path...))
+	}
+	return paths
+}
+
+// move is a step back towards the start of the matrix to i, j costing cost
+// op is the zero EditOp for equal characters
+type move struct {
+	i, j int
+	cost int
+	op   EditOp
+}
+
+// push adds the steps back towards the start of the matrix that leave the
+// least to pay and still fit in the budget
+// matrix[i][j] is the least left to pay to get to the start so walks that
+// can not finish are never followed, from the end of the matrix the steps
+// taken are the ones costing exactly what is left
+// taking the ones leaving the least makes edits as late in the password as
+// they can be
+// they are pushed in reverse so inserts are walked first, then deletes, then
+// replaces or equal characters and then swaps
+func (it *PathIterator) push(step pathStep) {
+	matrix := it.matrix
+	i, j := step.i, step.j
+	cost := matrix[i][j]
+	wordLen, passwordLen := len(matrix[0])-1, len(matrix)-1
+
+	var moves [4]move
+	n := 0
+	// swapping the last two characters
+	if it.word != nil && distance.IsSwap(it.word, it.password, i, j) {
+		moves[n] = move{i - 2, j - 2, it.costs.Swap, EditOp{Swap, i - 2, j - 2}}
+		n++
+	}
+	// replace or equal
+	if i > 0 && j > 0 {
+		replace := it.costs.Replace
+		if it.word != nil {
+			replace = it.costs.ReplaceCost(it.word[j-1], it.password[i-1])
+		} else if matrix[i-1][j-1] == cost {
+			// without the words equal characters are the ones costing nothing
+			replace = 0
+		}
+		moves[n] = move{i - 1, j - 1, replace, EditOp{Replace, i - 1, j - 1}}
+		if replace == 0 {
+			moves[n].op = EditOp{}
+		}
+		n++
+	}
+	// this is deleting on from the word
+	if j > 0 {
+		moves[n] = move{i, j - 1, it.costs.Delete, EditOp{Delete, i, j - 1}}
+		n++
+	}
+	// this is insert
+	if i > 0 {
+		moves[n] = move{i - 1, j, it.costs.InsertCost(i, j, wordLen, passwordLen), EditOp{Insert, i - 1, j}}
+		n++
+	}
+
+	// choose the path of least resistence
+	least := maxint
+	for _, m := range moves[:n] {
+		if left := matrix[m.i][m.j]; it.fits(step, m) && left < least {
+			least = left
+		}
+	}
+	for _, m := range moves[:n] {
+		if it.fits(step, m) && matrix[m.i][m.j] == least {
+			it.add(step, m)
+		}
+	}
+}
+
+// fits checks a path making m after step can still be finished in the budget
+func (it *PathIterator) fits(step pathStep, m move) bool {
+	return step.spent+m.cost+it.matrix[m.i][m.j] <= it.budget
+}
+
+// add pushes the step made by moving from from
+// the walks from the same place share its ops so nothing is copied
+func (it *PathIterator) add(from pathStep, m move) {
+	if m.op.Op == 0 {
+		it.stack = append(it.stack, pathStep{m.i, m.j, from.spent, from.ops})
+		return
+	}
+	it.ops = append(it.ops, opNode{m.op, from.ops})
+	it.stack = append(it.stack, pathStep{m.i, m.j, from.spent + m.cost, len(it.ops) - 1})
+}
+
+// appendPathKey appends what identifies the edits of a path to key
+// only the operation and where it is in the password make the rules
+func appendPathKey(key []byte, path []EditOp) []byte {
+	for _, op := range path {
+		key = append(key, byte(op.Op))
+		key = strconv.AppendInt(key, int64(op.P), 10)
+		key = append(key, ',')
+	}
+	return key
+}
@@ -0,0 +1,324 @@
+package rulegen
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/coolbry95/magicmachine/distance"
+	"github.com/coolbry95/passutils/ruleprocessor/rules"
+)
+
+func TestPrettyPrint(t *testing.T) {
+}
+
+func TestGenerateLevenshteinRules(t *testing.T) {
+	var paths = []struct {
+		word, password string
+		out            string
+	}{
+		{"password", "passw0rd", "[[replace 5]]"},
+		{"password", "password", "[[]]"},
+		{"love", "loves", "[[insert 4]]"},
+		{"abab", "baba", "[[delete 0 insert 3] [insert 0 delete 4] [swap 0 swap 2]]"},
+		// swapped characters are a single edit
+		{"password", "apssword", "[[swap 0]]"},
+		{"password", "passwodr", "[[swap 6]]"},
+		{"password", "Pasword1", "[[replace 0 delete 3 insert 7]]"},
+	}
+
+	for _, test := range paths {
+		out := GenerateLevenshteinRules([]rune(test.word), []rune(test.password))
+		if fmt.Sprint(out) != test.out {
+			t.Errorf("%s %s: should be %s, got %v", test.word, test.password, test.out, out)
+		}
+	}
+}
+
+func TestReverseRecurse(t *testing.T) {
+	matrix := distance.LevenshteinMatrix([]rune("abab"), []rune("baba"))
+	out := ReverseRecurse(matrix, len(matrix)-1, len(matrix[0])-1, 0)
+	if len(out) != 2 {
+		t.Errorf("should be 2 paths, got %v", out)
+	}
+}
+
+func TestWeightedPaths(t *testing.T) {
+	var paths = []struct {
+		word, password string
+		costs          distance.Costs
+		out            string
+	}{
+		{"love", "lolove", distance.UnitCosts(), "[[insert 2 insert 3]]"},
+		{"love", "lolove", distance.HashcatCosts(), "[[insert 0 insert 1]]"},
+		// one block at an end is cheaper than one at each end
+		{"aa", "aaaa", distance.Costs{Insert: 9, Delete: 1, Replace: 1, Case: 1, Swap: 1, PrefixOpen: 5, PrefixExtend: 1, SuffixOpen: 5, SuffixExtend: 1}, "[[insert 2 insert 3]]"},
+	}
+
+	for _, test := range paths {
+		out := WeightedPaths([]rune(test.word), []rune(test.password), test.costs, 0).All()
+		if fmt.Sprint(out) != test.out {
+			t.Errorf("%s %s %v: should be %s, got %v", test.word, test.password, test.costs, test.out, out)
+		}
+	}
+}
+
+// repeated characters have huge numbers of paths
+var manyPaths = [2]string{strings.Repeat("a", 10), strings.Repeat("b", 12) + strings.Repeat("a", 5)}
+
+func TestPaths(t *testing.T) {
+	word, password := []rune(manyPaths[0]), []rune(manyPaths[1])
+	edits := distance.OSA(word, password)
+
+	it := Paths(word, password, 50)
+	seen := make(map[string]bool)
+	n := 0
+	for path, ok := it.Next(); ok; path, ok = it.Next() {
+		n++
+		if len(path) != edits {
+			t.Errorf("path should have %d edits, got %v", edits, path)
+		}
+		if seen[fmt.Sprint(path)] {
+			t.Errorf("path %v is repeated", path)
+		}
+		seen[fmt.Sprint(path)] = true
+	}
+	if n != 50 {
+		t.Errorf("should stop at 50 paths, got %d", n)
+	}
+
+	// every path of a small password
+	if all := Paths([]rune("abab"), []rune("baba"), 0).All(); len(all) != 3 {
+		t.Errorf("should be 3 paths, got %v", all)
+	}
+}
+
+func TestPathsDeduplicate(t *testing.T) {
+	matrix := distance.LevenshteinMatrix([]rune("abab"), []rune("baba"))
+	it := newPathIterator(matrix, len(matrix)-1, len(matrix[0])-1, 0, 0)
+	// walking the same way twice makes the same paths
+	it.stack = append(it.stack, it.stack[0])
+
+	if all := it.All(); len(all) != 2 {
+		t.Errorf("should be 2 paths, got %v", all)
+	}
+}
+
+// legacyOp is an edit the way the recursive walk named it
+type legacyOp struct {
+	Op   string
+	P    int
+	Word int
+}
+
+// legacyPaths is the recursive walk paths were made with before the
+// PathIterator, kept so the benchmarks can be compared against it
+// every path is built in a new matrix and the edits are named by strings
+func legacyPaths(word, password []rune) [][]legacyOp {
+	matrix := distance.LevenshteinMatrix(word, password)
+	dist := matrix[len(matrix)-1][len(matrix[0])-1]
+
+	var paths [][]legacyOp
+	for _, path := range legacyRecurse(matrix, len(matrix)-1, len(matrix[0])-1, 0) {
+		if len(path) <= dist {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// legacyRecurse walks matrix backwards from i, j the way ReverseRecurse did
+func legacyRecurse(matrix [][]int, i, j, pathLen int) [][]legacyOp {
+	if i == 0 && j == 0 || pathLen > matrix[len(matrix)-1][len(matrix[0])-1] {
+		return make([][]legacyOp, pathLen, pathLen)
+	}
+
+	cost := matrix[i][j]
+	var edit [][]legacyOp
+
+	costInsert, costDelete, costEqualReplace := maxint, maxint, maxint
+	if i > 0 {
+		costInsert = matrix[i-1][j]
+	}
+	if j > 0 {
+		costDelete = matrix[i][j-1]
+	}
+	if i > 0 && j > 0 {
+		costEqualReplace = matrix[i-1][j-1]
+	}
+	costMin := min(costInsert, min(costDelete, costEqualReplace))
+
+	if costInsert == costMin {
+		for _, path := range legacyRecurse(matrix, i-1, j, pathLen+1) {
+			edit = append(edit, append(path, legacyOp{"insert", i - 1, j}))
+		}
+	}
+	if costDelete == costMin {
+		for _, path := range legacyRecurse(matrix, i, j-1, pathLen+1) {
+			edit = append(edit, append(path, legacyOp{"delete", i, j - 1}))
+		}
+	}
+	if costEqualReplace == costMin {
+		if costEqualReplace == cost {
+			edit = append(edit, legacyRecurse(matrix, i-1, j-1, pathLen)...)
+		} else {
+			for _, path := range legacyRecurse(matrix, i-1, j-1, pathLen+1) {
+				edit = append(edit, append(path, legacyOp{"replace", i - 1, j - 1}))
+			}
+		}
+	}
+	return edit
+}
+
+// legacySimpleRules is SimpleHashcatRules the way it was before the
+// ruleEncoder, every function is its own string made by fmt.Sprintf
+func legacySimpleRules(word, password []rune, operations []legacyOp) []string {
+	if string(word) == string(password) {
+		return []string{":"}
+	}
+
+	temp := make([]rune, len(word))
+	copy(temp, word)
+	r := []string{}
+
+	for _, op := range operations {
+		if op.Op == "insert" {
+			r = append(r, fmt.Sprintf("i%c%c", rules.ToAlpha(op.P), password[op.P]))
+			temp = rules.InsertAtN(temp, op.P, password[op.P])
+		} else if op.Op == "delete" {
+			r = append(r, fmt.Sprintf("D%c", rules.ToAlpha(op.P)))
+			temp = rules.DeleteN(temp, op.P)
+		} else if op.Op == "replace" {
+			r = append(r, fmt.Sprintf("o%c%c", rules.ToAlpha(op.P), password[op.P]))
+			temp = rules.OverwriteAtN(temp, op.P, password[op.P])
+		}
+	}
+
+	if string(temp) == string(password) {
+		return r
+	}
+	return nil
+}
+
+// fewPaths are words the recursive walk can still build every path of
+// on manyPaths it runs out of memory
+var fewPaths = [2]string{strings.Repeat("a", 6), strings.Repeat("b", 7) + strings.Repeat("a", 3)}
+
+func BenchmarkPaths(b *testing.B) {
+	for _, bench := range []struct {
+		name  string
+		words [2]string
+	}{
+		{"few", fewPaths},
+		{"many", manyPaths},
+	} {
+		word, password := []rune(bench.words[0]), []rune(bench.words[1])
+		b.Run(bench.name, func(b *testing.B) {
+			// the recursive walk builds every path, not only the first MaxPaths
+			if bench.words == fewPaths {
+				b.Run("baseline", func(b *testing.B) {
+					b.ReportAllocs()
+					for n := 0; n < b.N; n++ {
+						legacyPaths(word, password)
+					}
+				})
+			}
+			b.Run("fresh", func(b *testing.B) {
+				b.ReportAllocs()
+				for n := 0; n < b.N; n++ {
+					Paths(word, password, DefaultOptions().MaxPaths).All()
+				}
+			})
+			// a worker walks into the same matrix and iterator every time
+			b.Run("reused", func(b *testing.B) {
+				var m distance.Matrix
+				var it PathIterator
+				costs := distance.UnitCosts()
+				b.ReportAllocs()
+				for n := 0; n < b.N; n++ {
+					it.walk(m.Weighted(word, password, costs), word, password, costs, DefaultOptions().MaxPaths)
+					for _, ok := it.Next(); ok; _, ok = it.Next() {
+					}
+				}
+			})
+		})
+	}
+}
+
+// benchmarkPasswords are common cracked passwords and the words they come from
+var benchmarkPasswords = [][2]string{
+	{"password", "password1"},
+	{"password", "P@ssw0rd"},
+	{"iloveyou", "iloveyou2"},
+	{"monkey", "monkey123"},
+	{"dragon", "Dragon!"},
+	{"sunshine", "5unsh1ne"},
+	{"princess", "princess12"},
+	{"qwerty", "qwerty1!"},
+	{"football", "f00tball"},
+	{"michael", "Michael1987"},
+	{"shadow", "shad0w99"},
+	{"jessica", "jessica!!"},
+	{"letmein", "letmein123"},
+	{"superman", "Superman2"},
+	{"baseball", "baseball1"},
+	{"welcome", "Welcome1!"},
+	{"charlie", "chralie"},
+	{"trustno", "trustno1"},
+	{"master", "mast3r"},
+	{"hello", "hellohello"},
+}
+
+func BenchmarkGenerateHashcatRules(b *testing.B) {
+	g := NewGenerator(nil, DefaultOptions())
+	// a new workspace for every word is how much memory the pool saves
+	b.Run("fresh", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			for _, p := range benchmarkPasswords {
+				g.generateHashcatRules(new(workspace), p[0], p[1], ":")
+			}
+		}
+	})
+	b.Run("reused", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			for _, p := range benchmarkPasswords {
+				g.GenerateHashcatRules(p[0], p[1], ":")
+			}
+		}
+	})
+}
+
+// BenchmarkSimpleRules compares making the simple rules of every path with
+// the recursive walk and fmt.Sprintf against a reused workspace
+func BenchmarkSimpleRules(b *testing.B) {
+	b.Run("baseline", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			for _, p := range benchmarkPasswords {
+				word, password := []rune(p[0]), []rune(p[1])
+				for _, path := range legacyPaths(word, password) {
+					legacySimpleRules(word, password, path)
+				}
+			}
+		}
+	})
+	b.Run("reused", func(b *testing.B) {
+		var w workspace
+		costs := distance.UnitCosts()
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			for _, p := range benchmarkPasswords {
+				w.setWords(p[0], p[1])
+				w.paths.walk(w.matrix.Weighted(w.word, w.password, costs), w.word, w.password, costs, 0)
+				for path, ok := w.paths.Next(); ok; path, ok = w.paths.Next() {
+					w.rule.reset()
+					if simpleHashcatRules(&w.rule, w.word, w.password, path) {
+						w.rule.strings(0)
+					}
+				}
+			}
+		}
+	})
+}
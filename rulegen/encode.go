@@ -0,0 +1,98 @@
+package rulegen
+
+import (
+	"unicode/utf8"
+
+	"github.com/coolbry95/passutils/ruleprocessor/rules"
+)
+
+// ruleEncoder writes the functions of a hashcat rule into one buffer
+// most rules made are longer than the best rule and thrown away so rules are
+// only made into strings when they are kept
+type ruleEncoder struct {
+	buf   []byte
+	funcs []span
+}
+
+// span is where a function is in the buffer
+type span struct {
+	start, end int
+}
+
+// reset empties the rule keeping its memory
+func (e *ruleEncoder) reset() {
+	e.buf = e.buf[:0]
+	e.funcs = e.funcs[:0]
+}
+
+// len is how many functions the rule has
+func (e *ruleEncoder) len() int {
+	return len(e.funcs)
+}
+
+// function returns the function at i
+// changing it changes the rule
+func (e *ruleEncoder) function(i int) []byte {
+	s := e.funcs[i]
+	return e.buf[s.start:s.end]
+}
+
+// noop checks if the rule is only the rule doing nothing
+func (e *ruleEncoder) noop() bool {
+	return len(e.funcs) == 1 && string(e.function(0)) == ":"
+}
+
+// begin starts the function named f
+func (e *ruleEncoder) begin(f byte) {
+	e.funcs = append(e.funcs, span{len(e.buf), len(e.buf)})
+	e.buf = append(e.buf, f)
+}
+
+// end finishes the function begin started
+func (e *ruleEncoder) end() {
+	e.funcs[len(e.funcs)-1].end = len(e.buf)
+}
+
+// char adds a character to the function
+func (e *ruleEncoder) char(r rune) {
+	var b [utf8.UTFMax]byte
+	n := utf8.EncodeRune(b[:], r)
+	e.buf = append(e.buf, b[:n]...)
+}
+
+// pos adds a position to the function the way hashcat writes it, 0-9 then A-Z
+func (e *ruleEncoder) pos(n int) {
+	e.char(rules.ToAlpha(n))
+}
+
+// add adds the function f that has no arguments
+func (e *ruleEncoder) add(f byte) {
+	e.begin(f)
+	e.end()
+}
+
+// addN adds the function f at position n
+func (e *ruleEncoder) addN(f byte, n int) {
+	e.begin(f)
+	e.pos(n)
+	e.end()
+}
+
+// addNX adds the function f at position n with the character x
+func (e *ruleEncoder) addNX(f byte, n int, x rune) {
+	e.begin(f)
+	e.pos(n)
+	e.char(x)
+	e.end()
+}
+
+// strings returns the functions of the rule with room for extra more
+func (e *ruleEncoder) strings(extra int) []string {
+	// one string holds every function
+	all := string(e.buf)
+	out := make([]string, len(e.funcs), len(e.funcs)+extra)
+	for i, s := range e.funcs {
+		out[i] = all[s.start:s.end]
+	}
+	return out
+}
@@ -0,0 +1,36 @@
+package rulegen
+
+import (
+	"testing"
+)
+
+func TestRuleEncoder(t *testing.T) {
+	var e ruleEncoder
+	e.add(':')
+	if !e.noop() {
+		t.Errorf("should be the rule doing nothing")
+	}
+
+	e.reset()
+	e.addN('D', 3)
+	e.addNX('i', 12, 'é')
+	e.begin('s')
+	e.char('a')
+	e.char('@')
+	e.end()
+	e.add('c')
+
+	if e.len() != 4 || e.noop() {
+		t.Errorf("should be 4 functions, got %d", e.len())
+	}
+	if out := RuleLine(e.strings(0)); out != "D3 iCé sa@ c" {
+		t.Errorf("should be D3 iCé sa@ c, got %s", out)
+	}
+
+	// changing a function changes the rule
+	e.function(0)[0] = '['
+	out := e.strings(1)
+	if RuleLine(out) != "[3 iCé sa@ c" || cap(out) != 5 {
+		t.Errorf("should be [3 iCé sa@ c with room for 1 more, got %v", out)
+	}
+}
@@ -0,0 +1,853 @@
+// Package rulegen reverses passwords to source words and generates the hashcat
+// rules turning the source words back into the passwords
+package rulegen
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/coolbry95/magicmachine/distance"
+	"github.com/coolbry95/magicmachine/spell"
+	"github.com/coolbry95/passutils/ruleprocessor/rules"
+)
+
+// Options holds the tuning for word and rule generation
+type Options struct {
+	// word generation tuning
+	MaxWordDist int
+	MaxWords    int
+	MoreWords   bool
+	SimpleWords bool
+
+	// rule generation finetuning
+	MaxRuleLen  int
+	MaxRules    int
+	MoreRules   bool
+	SimpleRules bool
+	BruteRules  bool
+	// most edit paths turned into rules for each word, 0 for every one
+	// passwords with many repeated characters can have millions of paths
+	MaxPaths int
+	// what each edit costs, the cheapest paths are made into rules
+	// the zero Costs are UnitCosts
+	Costs distance.Costs
+	// split passwords made of several words into the words
+	// only used with spell checkers that are a spell.Segmenter
+	Segment bool
+	// replay every rule and drop the ones that do not produce the password
+	Verify bool
+	// normalize passwords before looking up words so accented and full width
+	// passwords find their words, the rules are still made from the password
+	// as it is
+	Normalize spell.Normalization
+
+	// Debugging options
+	Verbose bool
+	Debug   bool
+	// force word to use
+	Word  string
+	Quiet bool
+}
+
+// DefaultOptions returns the Options used by the magicmachine command
+func DefaultOptions() Options {
+	return Options{
+		MaxWordDist: spell.SuggestDistance,
+		MaxWords:    5,
+		MaxRuleLen:  15,
+		MaxRules:    5,
+		MaxPaths:    1000,
+		Costs:       distance.UnitCosts(),
+	}
+}
+
+// Generator reverses passwords using a spell checker
+// a Generator is safe to use from many goroutines when its spell checker is
+type Generator struct {
+	speller spell.Speller
+	opts    Options
+
+	// workspaces are kept for the next word so each worker reuses its memory
+	workspaces sync.Pool
+}
+
+// NewGenerator returns a Generator looking up source words with m
+func NewGenerator(m spell.Speller, opts Options) *Generator {
+	return &Generator{
+		speller: m,
+		opts:    opts,
+	}
+}
+
+// Options returns the options the Generator was made with
+func (g *Generator) Options() Options {
+	return g.opts
+}
+
+// Analyze reverses a password to source words and generates the rules turning
+// each of the words back into the password
+func (g *Generator) Analyze(password string) []Word {
+
+	// generate words based on the password
+	// when debugging with Options.Word the forced word is used instead
+	words := g.GenerateWords(password)
+
+	for i, word := range words {
+		// generate a list of hashcat rules for each suggestion
+		words[i].Rules = g.GenerateHashcatRules(word.Suggestion, word.Password, word.PreRule)
+
+		if g.opts.Verify {
+			words[i].Rules, words[i].FailedRules = g.VerifyRules(word.Suggestion, password, words[i].Rules)
+		}
+	}
+
+	return words
+}
+
+// VerifyRules replays each rule on the word and splits the rules into the
+// ones that produce the password and the ones that do not
+func (g *Generator) VerifyRules(word, password string, hashcatRules Rules) (Rules, Rules) {
+	var verified Rules
+	var failed Rules
+
+	for _, hashcatRule := range hashcatRules {
+		if rules.ApplyRules(hashcatRule, word) == password {
+			verified = append(verified, hashcatRule)
+		} else {
+			if g.opts.Debug {
+				log.Printf("verification failed: P: %s, W: %s, R: %s\n", password, word, RuleLine(hashcatRule))
+			}
+			failed = append(failed, hashcatRule)
+		}
+	}
+
+	return verified, failed
+}
+
+// Rules is a list of hashcat rules made up of single rule functions
+type Rules [][]string
+
+func (r Rules) String() string {
+	var rule string
+	for _, h := range r {
+		for _, in := range h {
+			rule += in + " "
+		}
+		rule += "\n"
+	}
+	return rule
+}
+
+// RuleLine formats a single hashcat rule the way it is written to a rule file
+func RuleLine(r []string) string {
+	return strings.Join(r, " ")
+}
+
+func (r Rules) Len() int           { return len(r) }
+func (r Rules) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r Rules) Less(i, j int) bool { return len(r[i]) < len(r[j]) }
+
+// Paths returns the edit paths turning word into password that rules are made from
+// they are the paths costing the least with Options.Costs and there are at
+// most Options.MaxPaths of them
+func (g *Generator) Paths(word, password string) *PathIterator {
+	return WeightedPaths([]rune(word), []rune(password), g.costs(), g.opts.MaxPaths)
+}
+
+// costs returns Options.Costs, UnitCosts when they are not set
+func (g *Generator) costs() distance.Costs {
+	if g.opts.Costs == (distance.Costs{}) {
+		return distance.UnitCosts()
+	}
+	return g.opts.Costs
+}
+
+// GenerateHashcatRules generates rules turning suggestion into password
+// password is the pre-analyzed password so the rule undoing preRule is added
+// to the end of every rule
+func (g *Generator) GenerateHashcatRules(suggestion, password, preRule string) Rules {
+	w := g.getWorkspace()
+	defer g.putWorkspace(w)
+	return g.generateHashcatRules(w, suggestion, password, preRule)
+}
+
+// generateHashcatRules is GenerateHashcatRules using the memory of w
+// the rules are only made into strings when they are as short as the best
+// rule so far
+func (g *Generator) generateHashcatRules(w *workspace, suggestion, password, preRule string) Rules {
+	// words split by segmentation have their spaces purged first
+	// the rest of the rule is for the joined words
+	purge := strings.Contains(suggestion, " ")
+	if purge {
+		suggestion = strings.Replace(suggestion, " ", "", -1)
+	}
+
+	// the functions added to every rule
+	extra := 0
+	if purge {
+		extra++
+	}
+	if _, ok := undoPreanalysis[preRule]; ok {
+		extra++
+	}
+
+	w.setWords(suggestion, password)
+	costs := g.costs()
+	w.paths.walk(w.matrix.Weighted(w.word, w.password, costs), w.word, w.password, costs, g.opts.MaxPaths)
+
+	var hashcatRulesCollection Rules
+	bestFoundRuleLength := maxint
+
+	// generate a hashcat rule for each path
+	for levRule, ok := w.paths.Next(); ok; levRule, ok = w.paths.Next() {
+		var made bool
+		if g.opts.SimpleRules {
+			w.rule.reset()
+			made = simpleHashcatRules(&w.rule, w.word, w.password, levRule)
+		} else {
+			made = g.advancedHashcatRules(w, levRule)
+		}
+
+		if !made {
+			if g.opts.Quiet {
+				log.Printf("processing failed")
+			}
+			continue
+		}
+
+		// the rule doing nothing is replaced by the functions added
+		ruleLength := w.rule.len() + extra
+		if extra > 0 && w.rule.noop() {
+			ruleLength = extra
+		}
+
+		if ruleLength > g.opts.MaxRuleLen {
+			continue
+		}
+		if !g.opts.MoreRules {
+			if ruleLength > bestFoundRuleLength {
+				if g.opts.Debug {
+					log.Printf("best rule length exceeded")
+				}
+				continue
+			}
+			if ruleLength < bestFoundRuleLength {
+				bestFoundRuleLength = ruleLength
+				hashcatRulesCollection = hashcatRulesCollection[:0]
+			}
+		}
+
+		hashcatRule := undoPreRule(w.rule.strings(extra), preRule)
+		if purge {
+			hashcatRule = purgeSpaces(hashcatRule)
+		}
+		hashcatRulesCollection = append(hashcatRulesCollection, hashcatRule)
+	}
+
+	// the shortest rules first
+	sort.Stable(hashcatRulesCollection)
+	return hashcatRulesCollection
+}
+
+// purgeSpaces adds the rule removing every space to the start of hashcatRule
+func purgeSpaces(hashcatRule []string) []string {
+	if len(hashcatRule) == 1 && hashcatRule[0] == ":" {
+		return []string{"@ "}
+	}
+	return append([]string{"@ "}, hashcatRule...)
+}
+
+// Word holds information for generating a hashcat rule
+type Word struct {
+	// edit distance from the suggestion to the analyzed password
+	Distance int `json:"distance"`
+	// source word found for the password
+	Suggestion string `json:"suggestion"`
+	// password after the pre-analysis rule was applied
+	Password string `json:"analyzed_password"`
+	// password as it was given to Analyze
+	Original string `json:"-"`
+	// pre-analysis rule applied to the password
+	PreRule string `json:"pre_rule"`
+	// rules turning the suggestion into the original password
+	Rules Rules `json:"rules"`
+	// rules dropped by verification
+	FailedRules Rules `json:"failed_rules,omitempty"`
+
+	bestRuleLength int
+}
+
+// Words is an alias to []Word
+type Words []Word
+
+func (w Words) Len() int           { return len(w) }
+func (w Words) Swap(i, j int)      { w[i], w[j] = w[j], w[i] }
+func (w Words) Less(i, j int) bool { return w[i].Distance < w[j].Distance }
+
+// preanalysisRules are applied to the password before looking for words
+var preanalysisRules = []string{":", "r", "}", "{"}
+
+// undoPreanalysis holds the rule that reverses each of the preanalysisRules
+var undoPreanalysis = map[string]string{
+	"r": "r",
+	"}": "{",
+	"{": "}",
+}
+
+// undoPreRule adds the rule reversing preRule to the end of hashcatRule so
+// that the rule turns the word into the original password
+func undoPreRule(hashcatRule []string, preRule string) []string {
+	undo, ok := undoPreanalysis[preRule]
+	if !ok {
+		return hashcatRule
+	}
+
+	// no need to keep the noop rule around
+	if len(hashcatRule) == 1 && hashcatRule[0] == ":" {
+		return []string{undo}
+	}
+
+	return append(hashcatRule, undo)
+}
+
+// GenerateWords finds the source words for a password
+func (g *Generator) GenerateWords(password string) []Word {
+
+	var words []Word
+	var wordsCollection []Word
+
+	// collect best edit distance
+	bestFoundDistance := 9999
+
+	preanalysisRules := preanalysisRules
+	if !g.opts.BruteRules {
+		preanalysisRules = preanalysisRules[:1]
+	}
+
+	var prePassword string
+	for _, preRule := range preanalysisRules {
+		prePassword = rules.ApplyRules([]string{preRule}, password)
+
+		// the password is only normalized to look up words
+		// the rules are made from prePassword
+		lookup := g.opts.Normalize.Normalize(prePassword)
+
+		var suggestions []string
+		if len(g.opts.Word) > 0 {
+			suggestions = []string{g.opts.Word}
+		} else if g.opts.SimpleWords {
+			suggestions = g.generateSimpleWords(lookup)
+		} else {
+			suggestions = g.generateAdvancedWords(lookup)
+		}
+
+		hashset1 := make(map[string]struct{})
+
+		for _, val := range suggestions {
+			hashset1[val] = struct{}{}
+		}
+
+		// rules for each of the suggestions
+		for _, suggestion := range suggestions {
+			suggestion = strings.Replace(suggestion, " ", "", -1)
+			suggestion = strings.Replace(suggestion, "-", "", -1)
+
+			if _, ok := hashset1[suggestion]; !ok {
+				suggestions = append(suggestions, suggestion)
+				hashset1[suggestion] = struct{}{}
+			}
+		}
+
+		/*
+			// TODO what is the point of this??
+			// debugging??
+			if len(suggestions) != len(hashset1) {
+				// make these sorted
+				//fmt.Println(suggestions)
+				//fmt.Println(hashset1)
+			}
+		*/
+
+		for _, suggestion := range suggestions {
+			// the spaces of segmented words are removed with a single rule
+			// swaps are a single edit the same as in the paths and the spell checker
+			joined := strings.Replace(suggestion, " ", "", -1)
+
+			temp := Word{
+				Suggestion:     suggestion,
+				Distance:       distance.OSA([]rune(joined), []rune(prePassword)),
+				Password:       prePassword,
+				Original:       password,
+				PreRule:        preRule,
+				bestRuleLength: 9999,
+			}
+
+			words = append(words, temp)
+		}
+	}
+
+	// stable so the order the speller ranked the words in is kept
+	sort.Stable(Words(words))
+
+	for _, word := range words {
+		if !g.opts.MoreWords {
+			if word.Distance < bestFoundDistance {
+				bestFoundDistance = word.Distance
+			}
+		} else if word.Distance > bestFoundDistance {
+			if g.opts.Debug {
+				log.Println("best found distance suboptimal")
+			}
+			break
+		}
+
+		// filter words with large edit distance
+		if word.Distance <= g.opts.MaxWordDist {
+			wordsCollection = append(wordsCollection, word)
+		} else {
+			if g.opts.Debug {
+				log.Println("max distance exceeded")
+			}
+		}
+	}
+
+	if g.opts.MaxWords > 0 {
+		if g.opts.MaxWords > len(wordsCollection) {
+			wordsCollection = wordsCollection[:]
+		} else {
+			wordsCollection = wordsCollection[:g.opts.MaxWords]
+		}
+	}
+
+	return wordsCollection
+}
+
+func (g *Generator) generateSimpleWords(password string) []string {
+	return g.speller.Suggest(password)
+}
+
+// leet speek translation map
+var leet = map[rune]rune{
+	'1': 'i',
+	'2': 'z',
+	'3': 'e',
+	'4': 'a',
+	'5': 's',
+	'6': 'b',
+	'7': 't',
+	'8': 'b',
+	'9': 'g',
+	'0': 'o',
+	'!': 'i',
+	'|': 'i',
+	'@': 'a',
+	'$': 's',
+	'+': 't',
+}
+
+// this is really expensive
+// so we make them stay so not to run them for every word
+// \PL is anything that is not a letter in any language
+var insertRegex = regexp.MustCompile(`^\PL*(?P<password>.+?)\PL*$`)
+var emailRegex = regexp.MustCompile(`(?i)^(?P<password>.+?)@[A-Z0-9.-]+\.[A-Z]{2,4}`)
+
+func (g *Generator) generateAdvancedWords(password string) []string {
+	// remove non alpha prefix and/or suffix
+	insertionMatches := insertRegex.FindStringSubmatch(password)
+	if insertionMatches != nil {
+		// only the last one
+		password = insertionMatches[len(insertionMatches)-1]
+
+	}
+
+	// match emails
+	emailMatches := emailRegex.FindStringSubmatch(password)
+	if emailMatches != nil {
+		// only do the last one
+		password = emailMatches[len(emailMatches)-1]
+	}
+
+	// common character matches to leet speak
+	var preanalysisPassword string
+	for _, c := range password {
+		if val, ok := leet[c]; ok {
+			preanalysisPassword += string(val)
+		} else {
+			preanalysisPassword += string(c)
+		}
+	}
+
+	password = preanalysisPassword
+
+	suggestions := g.generateSimpleWords(password)
+
+	// iloveyou is i love you
+	if g.opts.Segment {
+		if segmenter, ok := g.speller.(spell.Segmenter); ok {
+			if segmented := segmenter.Segment(strings.ToLower(password)); strings.Contains(segmented, " ") {
+				suggestions = append(suggestions, segmented)
+			}
+		}
+	}
+
+	return suggestions
+}
+
+// RuleWorks tests if a rule results in the correct managled word
+func RuleWorks(word []rune, password []rune, operations []EditOp) bool {
+	temp := make([]rune, len(word))
+	copy(temp, word)
+
+	for _, op := range operations {
+		if op.Op == Insert {
+			temp = rules.InsertAtN(temp, op.P, password[op.P])
+		} else if op.Op == Delete {
+			temp = rules.DeleteN(temp, op.P)
+		} else if op.Op == Replace {
+			temp = rules.OverwriteAtN(temp, op.P, password[op.P])
+		} else if op.Op == Swap {
+			temp = rules.SwapAtN(temp, op.P, op.P+1)
+		}
+	}
+
+	if string(temp) == string(password) {
+		return true
+	}
+
+	return false
+}
+
+// SimpleHashcatRules applies the basic hashcat rules based on delete, insert, replace
+func SimpleHashcatRules(word []rune, password []rune, operations []EditOp) []string {
+	var e ruleEncoder
+	if !simpleHashcatRules(&e, word, password, operations) {
+		return nil
+	}
+	return e.strings(0)
+}
+
+// simpleHashcatRules writes the rule SimpleHashcatRules makes to e and
+// returns false when it does not make the password
+func simpleHashcatRules(e *ruleEncoder, word []rune, password []rune, operations []EditOp) bool {
+	if string(word) == string(password) {
+		e.add(':')
+		return true
+	}
+
+	temp := make([]rune, len(word))
+	copy(temp, word)
+
+	for _, op := range operations {
+		if op.Op == Insert {
+			e.addNX('i', op.P, password[op.P])
+			temp = rules.InsertAtN(temp, op.P, password[op.P])
+		} else if op.Op == Delete {
+			e.addN('D', op.P)
+			temp = rules.DeleteN(temp, op.P)
+		} else if op.Op == Replace {
+			e.addNX('o', op.P, password[op.P])
+			temp = rules.OverwriteAtN(temp, op.P, password[op.P])
+		} else if op.Op == Swap {
+			e.begin('*')
+			e.pos(op.P)
+			e.pos(op.P + 1)
+			e.end()
+			temp = rules.SwapAtN(temp, op.P, op.P+1)
+		}
+	}
+
+	return string(temp) == string(password)
+}
+
+// **** TODO need to fix for new rule and change of rule
+// OMN and xMN
+// not all rules are here add more??
+
+// AdvancedHashcatRules applies all hashcat rules to a word
+func (g *Generator) AdvancedHashcatRules(passwordString, wordString string, perations []EditOp) []string {
+	w := &workspace{word: []rune(wordString), password: []rune(passwordString)}
+	if !g.advancedHashcatRules(w, perations) {
+		return nil
+	}
+	return w.rule.strings(0)
+}
+
+// advancedHashcatRules writes the rule AdvancedHashcatRules makes turning
+// w.word into w.password to w.rule and returns false when it does not make
+// the password
+func (g *Generator) advancedHashcatRules(w *workspace, perations []EditOp) bool {
+	password, word := w.password, w.word
+	e := &w.rule
+	e.reset()
+
+	// TODO
+	// can we do this earlier not in this function to save a fucntion call
+	if string(password) == string(word) {
+		e.add(':')
+		return true
+	}
+
+	// this holds the current mangled as rules are applied
+	wordRules := append(w.mangled[:0], word...)
+	defer func() { w.mangled = wordRules[:0] }()
+
+	var passwordLower int
+	var passwordUpper int
+	for _, r := range password {
+		if unicode.IsLower(r) {
+			passwordLower++
+		} else if unicode.IsUpper(r) {
+			passwordUpper++
+		}
+	}
+
+	for i, op := range perations {
+
+		if op.Op == Insert {
+			e.addNX('i', op.P, password[op.P])
+			wordRules = rules.InsertAtN(wordRules, op.P, password[op.P])
+		} else if op.Op == Delete {
+			e.addN('D', op.P)
+			wordRules = rules.DeleteN(wordRules, op.P)
+		} else if op.Op == Swap {
+			// swap made obsolete by prior global replacement
+			if wordRules[op.P] == password[op.P] && wordRules[op.P+1] == password[op.P+1] {
+				if g.opts.Debug {
+					fmt.Println("obsolete rule")
+				}
+			} else if op.P == 0 {
+				e.add('k')
+				wordRules = rules.SwapFront(wordRules)
+			} else if op.P == len(wordRules)-2 {
+				e.add('K')
+				wordRules = rules.SwapBack(wordRules)
+			} else {
+				// Swap any two characters (only adjacent swapping is supported)
+				e.begin('*')
+				e.pos(op.P)
+				e.pos(op.P + 1)
+				e.end()
+				wordRules = rules.SwapAtN(wordRules, op.P, op.P+1)
+			}
+		} else if op.Op == Replace {
+
+			// rule was made obsolete by prior global replacement
+			// test to see if word is greater than password to avoid index error
+			if len(wordRules) >= len(password) && wordRules[op.P] == password[op.P] {
+				if g.opts.Debug {
+					fmt.Println("obsolete rule")
+				}
+
+				// Case Toggle: Uppercased a letter
+			} else if unicode.IsLower(wordRules[op.P]) && unicode.ToUpper(wordRules[op.P]) == password[op.P] {
+				// Toggle the case of all characters in word (mixed cases)
+				if passwordUpper > 0 && passwordLower > 0 && RuleWorks(rules.ToggleCase(wordRules), password, perations[i+1:]) {
+					e.add('t')
+					wordRules = rules.ToggleCase(wordRules)
+					// Capitalize all letters
+				} else if RuleWorks(rules.Uppercase(wordRules), password, perations[i+1:]) {
+					e.add('u')
+					wordRules = rules.Uppercase(wordRules)
+					// Capitalize the first letter
+				} else if op.P == 0 && RuleWorks(rules.Capitalize(wordRules), password, perations[i+1:]) {
+					e.add('c')
+					wordRules = rules.Capitalize(wordRules)
+					// Toggle the case of characters at position N
+				} else {
+					e.addN('T', op.P)
+					wordRules = rules.ToggleAt(wordRules, op.P)
+				}
+
+				// Case Toggle Lowercased a letter
+			} else if unicode.IsUpper(wordRules[op.P]) && unicode.ToLower(wordRules[op.P]) == password[op.P] {
+				// Toggle the case of all characters in word (mixed cases)
+				if passwordUpper > 0 && passwordLower > 0 && RuleWorks(rules.ToggleCase(wordRules), password, perations[i+1:]) {
+					e.add('t')
+					wordRules = rules.ToggleCase(wordRules)
+					// Lowercase all letters
+				} else if RuleWorks(rules.Lowercase(wordRules), password, perations[i+1:]) {
+					e.add('l')
+					wordRules = rules.Lowercase(wordRules)
+					// Lowercase the first found character, uppercase the rest
+				} else if op.P == 0 && RuleWorks(rules.InvertCapitalize(wordRules), password, perations[i+1:]) {
+					e.add('C')
+					wordRules = rules.InvertCapitalize(wordRules)
+					// Toggle the case of characters at position N
+				} else {
+					e.addN('T', op.P)
+					wordRules = rules.ToggleAt(wordRules, op.P)
+				}
+
+				// Special case substitution of 'all' instances (1337 $p34k)
+			} else if unicode.IsLetter(wordRules[op.P]) && !unicode.IsLetter(password[op.P]) &&
+				RuleWorks(rules.Replace(wordRules[0:], wordRules[op.P], password[op.P]), password, perations[i+1:]) {
+
+				e.begin('s')
+				e.char(wordRules[op.P])
+				e.char(password[op.P])
+				e.end()
+				wordRules = rules.Replace(wordRules, wordRules[op.P], password[op.P])
+
+				// Replace next character with current
+			} else if op.P < len(password)-1 && op.P < len(wordRules)-1 &&
+				password[op.P] == password[op.P+1] && password[op.P] == wordRules[op.P+1] {
+				e.addN('.', op.P)
+				wordRules = rules.ReplaceNPlus(wordRules, op.P)
+
+				// Replace previous character with current
+			} else if op.P > 0 && op.Word > 0 && password[op.P] == password[op.P-1] && password[op.P] == wordRules[op.P-1] {
+				e.addN(',', op.P)
+				wordRules = rules.ReplaceNMinus(wordRules, op.P)
+
+				// ASCII increment
+			} else if wordRules[op.P]+1 == password[op.P] {
+				e.addN('+', op.P)
+				wordRules = rules.ASCIIIncrementPlus(wordRules, op.P)
+
+				// ASCII decrement
+			} else if wordRules[op.P]-1 == password[op.P] {
+				e.addN('-', op.P)
+				wordRules = rules.ASCIIIncrementMinus(wordRules, op.P)
+
+				// SHIFT left
+			} else if wordRules[op.P]<<1 == password[op.P] {
+				e.addN('L', op.P)
+				wordRules = rules.BitwiseShiftLeft(wordRules, op.P)
+
+				// SHIFT right
+			} else if wordRules[op.P]>>1 == password[op.P] {
+				e.addN('R', op.P)
+				wordRules = rules.BitwiseShiftRight(wordRules, op.P)
+
+				// Position based replacements.
+			} else {
+				e.addNX('o', op.P, password[op.P])
+				wordRules = rules.OverwriteAtN(wordRules, op.P, password[op.P])
+			}
+
+		}
+	}
+	// out of for loop
+
+	// these next things convert rules to append $ and prepend rules
+
+	// TODO
+	// possibility to have either what the rule is now or
+	// the rule swapped with these replacements
+
+	// Prefix rules
+	// inserts at the start of the password are made one after the other from
+	// the first character so they are prepended in reverse
+	prefix := 0
+	for prefix < e.len() && isInsertAt(e.function(prefix), prefix) {
+		prefix++
+	}
+	for i, j := 0, prefix-1; i < j; i, j = i+1, j-1 {
+		e.funcs[i], e.funcs[j] = e.funcs[j], e.funcs[i]
+	}
+	// iNX becomes ^X
+	for i := 0; i < prefix; i++ {
+		e.funcs[i].start++
+		e.buf[e.funcs[i].start] = '^'
+	}
+
+	// Appendix rules
+	// inserts at the end of the password are the last rules and each is made
+	// at the end of the word so far
+	lastAppendix := len(password) - 1
+	for i := e.len() - 1; i >= prefix && isInsertAt(e.function(i), lastAppendix); i-- {
+		e.funcs[i].start++
+		e.buf[e.funcs[i].start] = '$'
+		lastAppendix--
+	}
+
+	// Truncate left rules
+	for i := 0; i < e.len(); i++ {
+		f := e.function(i)
+		if f[0] != 'D' || rules.ToNumByte(f[1]) != 0 {
+			break
+		}
+		f[0] = '['
+		e.funcs[i].end = e.funcs[i].start + 1
+	}
+
+	// Truncate right rules
+	lastPostcut := len(password)
+	for i := 0; i < e.len(); i++ {
+		f := e.function(i)
+		if f[0] != 'D' || rules.ToNumByte(f[1]) < lastPostcut {
+			break
+		}
+		f[0] = ']'
+		e.funcs[i].end = e.funcs[i].start + 1
+	}
+
+	/*
+		// naive implementation of OMN
+		// will only work if the first rule is a delete
+		overwrite := 0
+		for i, hashcatRule := range needNewName {
+			if hashcatRule[0] == 'D' && i < len(password)-1 && needNewName[i+1] == 'D' {
+				overwrite++
+				needNewName[i] = ""
+			} else {
+				break
+			}
+		}
+		if overwrite > 0 {
+			var temp []string
+			temp = append(temp, fmt.Sprintf("O%c%c", rules.ToAlpha(0), rules.ToAlpha(overwrite)))
+			temp = append(temp, needNewName[:]...)
+			needNewName = temp
+		}
+	*/
+
+	// Check if rules result in the correct password
+	if string(wordRules) == string(password) {
+		return true
+	}
+
+	if g.opts.Quiet {
+		log.Printf("advanced processing failed: P: %s, M: %s, O: %s, %v\n", string(password), string(wordRules), string(word), e.strings(0))
+	}
+	return false
+}
+
+// isInsertAt checks if the function f inserts at position n
+func isInsertAt(f []byte, n int) bool {
+	return len(f) > 2 && f[0] == 'i' && rules.ToNumByte(f[1]) == n
+}
+
+// Reversible checks if a password is likely to be reversed to a source word
+func Reversible(password []rune) bool {
+	// check if a password is likely to be reversed
+	// skip numeric passwords
+	d := 0
+	for _, r := range password {
+		if unicode.IsDigit(r) {
+			d++
+		}
+	}
+	if d == len(password) {
+		return false
+	}
+
+	// skip with less than 25% alpha
+	// TODO based on entropy?
+	d = 0
+	for _, r := range password {
+		if unicode.IsLetter(r) {
+			d++
+		}
+	}
+	if d < len(password)/4 {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,381 @@
+package rulegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/coolbry95/magicmachine/distance"
+	"github.com/coolbry95/magicmachine/spell"
+	"github.com/coolbry95/passutils/ruleprocessor/rules"
+)
+
+// test in order that function is called
+
+var model *spell.Model
+
+var testWords = []string{
+	"password",
+	"test",
+	"tesing",
+}
+
+func init() {
+	model = spell.NewModel()
+
+	for _, word := range testWords {
+		model.CreateEntry(word)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Verify = true
+	opts.Word = "password"
+	g := NewGenerator(model, opts)
+
+	for _, password := range []string{"password", "Password1", "p4ssword", "1password"} {
+		words := g.Analyze(password)
+		if len(words) == 0 {
+			t.Errorf("%s: no words found", password)
+			continue
+		}
+		for _, word := range words {
+			if len(word.Rules) == 0 {
+				t.Errorf("%s: no rules for %s", password, word.Suggestion)
+			}
+			if len(word.FailedRules) > 0 {
+				t.Errorf("%s: rules failed verification %v", password, word.FailedRules)
+			}
+		}
+	}
+}
+
+func TestGenerateHashcatRules(t *testing.T) {
+	var costs = []struct {
+		costs distance.Costs
+		out   string
+	}{
+		// the fewest edits insert as late as they can
+		{distance.UnitCosts(), "i2l i3o"},
+		// prepending is cheaper than inserting
+		{distance.HashcatCosts(), "^o ^l"},
+	}
+
+	for _, test := range costs {
+		opts := DefaultOptions()
+		opts.Costs = test.costs
+		g := NewGenerator(model, opts)
+		out := g.GenerateHashcatRules("love", "lolove", ":")
+		if len(out) != 1 || RuleLine(out[0]) != test.out {
+			t.Errorf("%v: should be %s, got %v", test.costs, test.out, out)
+		}
+	}
+
+	// the zero Costs are UnitCosts
+	g := NewGenerator(model, Options{MaxRuleLen: 15})
+	if out := g.GenerateHashcatRules("love", "lolove", ":"); len(out) != 1 || RuleLine(out[0]) != "i2l i3o" {
+		t.Errorf("should be i2l i3o, got %v", out)
+	}
+
+	// more rules keeps every rule, the shortest first
+	opts := DefaultOptions()
+	opts.MoreRules = true
+	g = NewGenerator(model, opts)
+	out := g.GenerateHashcatRules("love", "evol1", ":")
+	if len(out) != 4 {
+		t.Errorf("should be 4 rules, got %v", out)
+	}
+	for i := 1; i < len(out); i++ {
+		if len(out[i]) < len(out[i-1]) {
+			t.Errorf("should be shortest first, got %v", out)
+		}
+	}
+}
+
+func TestGenerateWords(t *testing.T) {
+	g := NewGenerator(model, DefaultOptions())
+
+	words := g.GenerateWords("p@ssw0rd1")
+	if len(words) != 1 {
+		t.Fatalf("should be one word, got %v", words)
+	}
+	w := words[0]
+	if w.Suggestion != "password" || w.Distance != 3 || w.PreRule != ":" || w.Password != "p@ssw0rd1" {
+		t.Errorf("should be password at distance 3, got %+v", w)
+	}
+
+	// only the words closest to the password are kept
+	opts := DefaultOptions()
+	opts.Word = "test"
+	g = NewGenerator(model, opts)
+	if words := g.GenerateWords("test1"); len(words) != 1 || words[0].Suggestion != "test" || words[0].Distance != 1 {
+		t.Errorf("should be test at distance 1, got %v", words)
+	}
+}
+
+func TestGenerateSimpleWords(t *testing.T) {
+	g := NewGenerator(model, DefaultOptions())
+
+	var suggest = []struct {
+		in  string
+		out string
+	}{
+		{"pasword", "password"},
+		{"tset", "test"},
+		// simple words do not undo leet speak
+		{"p@$$w0rd", ""},
+	}
+
+	for _, test := range suggest {
+		out := g.generateSimpleWords(test.in)
+		if strings.Join(out, " ") != test.out {
+			t.Errorf("%s: should be %q, got %v", test.in, test.out, out)
+		}
+	}
+}
+
+func TestGenerateAdvancedWords(t *testing.T) {
+	g := NewGenerator(model, DefaultOptions())
+
+	var suggest = []struct {
+		in  string
+		out string
+	}{
+		// leet speak is undone
+		{"p@$$w0rd", "password"},
+		// letters are only looked up without what is around them
+		{"123password!!", "password"},
+		{"test@example.com", "test"},
+	}
+
+	for _, test := range suggest {
+		out := g.generateAdvancedWords(test.in)
+		if strings.Join(out, " ") != test.out {
+			t.Errorf("%s: should be %q, got %v", test.in, test.out, out)
+		}
+	}
+}
+
+func TestRuleWorks(t *testing.T) {
+	var works = []struct {
+		word, password string
+		ops            []EditOp
+		out            bool
+	}{
+		{"password", "passwords", []EditOp{{Insert, 8, 8}}, true},
+		{"password", "pasword", []EditOp{{Delete, 3, 3}}, true},
+		{"password", "apssword", []EditOp{{Swap, 0, 0}}, true},
+		{"password", "apssword", []EditOp{{Replace, 0, 0}}, false},
+		// every edit is applied, not only the ones that keep the length
+		{"password", "1passwrd", []EditOp{{Insert, 0, 0}, {Delete, 6, 5}}, true},
+	}
+
+	for _, test := range works {
+		if out := RuleWorks([]rune(test.word), []rune(test.password), test.ops); out != test.out {
+			t.Errorf("%s %s %v: should be %v, got %v", test.word, test.password, test.ops, test.out, out)
+		}
+	}
+}
+
+func TestSimpleHashcatRules(t *testing.T) {
+	out := SimpleHashcatRules([]rune("password"), []rune("passowrd"), []EditOp{{Swap, 4, 4}})
+	if RuleLine(out) != "*45" {
+		t.Errorf("should be *45, got %v", out)
+	}
+
+	// positions past 9 are letters
+	out = SimpleHashcatRules([]rune("passwordpassword"), []rune("passwordpasswrod"), []EditOp{{Swap, 13, 13}})
+	if RuleLine(out) != "*DE" {
+		t.Errorf("should be *DE, got %v", out)
+	}
+
+	// every rule made for long words makes the password
+	var long = [][2]string{
+		{"passwordpassword", "passwordpassw0rd1"},
+		{"supercalifragilistic", "supercal1fragilistic!"},
+		{"administrator", "admnistrat0r"},
+	}
+	for _, p := range long {
+		for _, path := range Paths([]rune(p[0]), []rune(p[1]), 0).All() {
+			out := SimpleHashcatRules([]rune(p[0]), []rune(p[1]), path)
+			if rules.ApplyRules(out, p[0]) != p[1] {
+				t.Errorf("%s %s: rule %v does not make the password", p[0], p[1], out)
+			}
+		}
+	}
+}
+
+func TestAdvancedHashcatRules(t *testing.T) {
+	g := NewGenerator(model, DefaultOptions())
+
+	// swaps come straight from the edits
+	var edits = []struct {
+		password string
+		out      string
+	}{
+		{"apssword", "k"},
+		{"passwodr", "K"},
+		{"passowrd", "*45"},
+		{"apsswodr", "k K"},
+		// inserts at the ends are prepended in reverse and appended in order
+		{"12password", "^2 ^1"},
+		{"password123", "$1 $2 $3"},
+		{"1password!", "^1 $!"},
+		{"Password1", "c $1"},
+	}
+
+	for _, test := range edits {
+		paths := Paths([]rune("password"), []rune(test.password), 0).All()
+		if len(paths) != 1 {
+			t.Fatalf("%s: should be one path, got %v", test.password, paths)
+		}
+		out := g.AdvancedHashcatRules(test.password, "password", paths[0])
+		if RuleLine(out) != test.out {
+			t.Errorf("%s: should be %s, got %v", test.password, test.out, out)
+		}
+		if rules.ApplyRules(out, "password") != test.password {
+			t.Errorf("%s: rule %v does not make the password", test.password, out)
+		}
+	}
+}
+
+func TestCaseLookAhead(t *testing.T) {
+	g := NewGenerator(model, DefaultOptions())
+
+	// case rules are only used when the edits after them still make the
+	// password, including the edits that change the length
+	var edits = []struct {
+		password string
+		ops      []EditOp
+		out      string
+	}{
+		{"PASSWRD", []EditOp{
+			{Replace, 0, 0}, {Replace, 1, 1}, {Replace, 2, 2}, {Replace, 3, 3},
+			{Replace, 4, 4}, {Delete, 5, 5}, {Replace, 5, 6}, {Replace, 6, 7},
+		}, "u D5"},
+		{"Passwrd", []EditOp{{Replace, 0, 0}, {Delete, 5, 5}}, "c D5"},
+	}
+
+	for _, test := range edits {
+		out := g.AdvancedHashcatRules(test.password, "password", test.ops)
+		if RuleLine(out) != test.out {
+			t.Errorf("%s: should be %s, got %v", test.password, test.out, out)
+		}
+		if rules.ApplyRules(out, "password") != test.password {
+			t.Errorf("%s: rule %v does not make the password", test.password, out)
+		}
+	}
+}
+
+func TestAdvancedSwapPositions(t *testing.T) {
+	g := NewGenerator(model, DefaultOptions())
+
+	// swap positions are written the way hashcat reads them, 0-9 then A-Z
+	var swaps = []struct {
+		word, password string
+		out            string
+	}{
+		{"password", "passowrd", "*45"},
+		{"passwordpassword", "passwordpasswrod", "*DE"},
+	}
+
+	for _, test := range swaps {
+		paths := Paths([]rune(test.word), []rune(test.password), 0).All()
+		if len(paths) != 1 {
+			t.Fatalf("%s: should be one path, got %v", test.password, paths)
+		}
+		out := g.AdvancedHashcatRules(test.password, test.word, paths[0])
+		if RuleLine(out) != test.out {
+			t.Errorf("%s: should be %s, got %v", test.password, test.out, out)
+		}
+		if rules.ApplyRules(out, test.word) != test.password {
+			t.Errorf("%s: rule %v does not make the password", test.password, out)
+		}
+	}
+}
+
+func TestReversible(t *testing.T) {
+	var passwords = []struct {
+		password   string
+		reversible bool
+	}{
+		{"password1", true},
+		{"123456", false},
+		{"1234567a", false},
+		{"p4ssw0rd", true},
+	}
+
+	for _, p := range passwords {
+		if out := Reversible([]rune(p.password)); out != p.reversible {
+			t.Errorf("%s: should be %v, got %v", p.password, p.reversible, out)
+		}
+	}
+}
+
+func TestSegmentedRules(t *testing.T) {
+	m := spell.NewModel()
+	if err := m.LoadWordList(strings.NewReader("i\nlove\nyou\njessica\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultOptions()
+	opts.Segment = true
+	opts.Verify = true
+	g := NewGenerator(m, opts)
+
+	for _, word := range g.Analyze("iloveyoujessica1") {
+		if word.Suggestion != "i love you jessica" {
+			continue
+		}
+		if len(word.Rules) == 0 {
+			t.Fatalf("no rules for %s, failed %v", word.Suggestion, word.FailedRules)
+		}
+		for _, r := range word.Rules {
+			if r[0] != "@ " {
+				t.Errorf("rule %v should start by purging spaces", r)
+			}
+		}
+		return
+	}
+	t.Errorf("i love you jessica should be a word")
+}
+
+// exactSpeller only suggests words it has exactly
+type exactSpeller map[string]bool
+
+func (e exactSpeller) Suggest(word string) []string {
+	if e[word] {
+		return []string{word}
+	}
+	return nil
+}
+
+func (e exactSpeller) Replace(string, string) {}
+
+func TestNormalizedWords(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Verify = true
+	opts.Normalize = spell.NFKC | spell.FoldCase | spell.StripDiacritics
+	g := NewGenerator(exactSpeller{"cafe": true}, opts)
+
+	for _, password := range []string{"Café", "CAFÉ1", "cafe", "ｃａｆｅ"} {
+		words := g.Analyze(password)
+		if len(words) == 0 || words[0].Suggestion != "cafe" {
+			t.Errorf("%s: cafe should be found, got %v", password, words)
+			continue
+		}
+		// the rules turn the word into the password as it is
+		if words[0].Password != password {
+			t.Errorf("%s: rules should be for the password, got %s", password, words[0].Password)
+		}
+		if len(words[0].Rules) == 0 {
+			t.Errorf("%s: no rules, failed %v", password, words[0].FailedRules)
+		}
+	}
+
+	// without normalizing the word is not found
+	g = NewGenerator(exactSpeller{"cafe": true}, DefaultOptions())
+	if words := g.Analyze("Café"); len(words) > 0 {
+		t.Errorf("Café should not be found, got %v", words)
+	}
+}
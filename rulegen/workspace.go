@@ -0,0 +1,45 @@
+package rulegen
+
+import (
+	"github.com/coolbry95/magicmachine/distance"
+)
+
+// workspace holds the memory a worker reuses for every word so the matrix,
+// paths and rules of each word are made in the same buffers
+// a workspace is only used by one goroutine at a time
+type workspace struct {
+	matrix distance.Matrix
+	paths  PathIterator
+	rule   ruleEncoder
+
+	// the word and password the rules are for and the word as the rules are
+	// applied to it
+	word, password, mangled []rune
+}
+
+// getWorkspace returns a workspace for a worker to use
+// it is given back with putWorkspace once the worker is done with it
+func (g *Generator) getWorkspace() *workspace {
+	if w, ok := g.workspaces.Get().(*workspace); ok {
+		return w
+	}
+	return &workspace{}
+}
+
+func (g *Generator) putWorkspace(w *workspace) {
+	g.workspaces.Put(w)
+}
+
+// setWords sets the word and password of the workspace
+func (w *workspace) setWords(word, password string) {
+	w.word = appendRunes(w.word[:0], word)
+	w.password = appendRunes(w.password[:0], password)
+}
+
+// appendRunes appends the characters of s to r
+func appendRunes(r []rune, s string) []rune {
+	for _, c := range s {
+		r = append(r, c)
+	}
+	return r
+}
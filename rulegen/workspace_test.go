@@ -0,0 +1,30 @@
+package rulegen
+
+import (
+	"testing"
+)
+
+func TestWorkspace(t *testing.T) {
+	g := NewGenerator(model, DefaultOptions())
+	w := g.getWorkspace()
+	w.setWords("café", "Café1")
+	if string(w.word) != "café" || string(w.password) != "Café1" {
+		t.Errorf("should be café and Café1, got %s and %s", string(w.word), string(w.password))
+	}
+
+	// a used workspace makes the same rules as a new one
+	for _, p := range benchmarkPasswords {
+		want := g.generateHashcatRules(new(workspace), p[0], p[1], ":")
+		got := g.generateHashcatRules(w, p[0], p[1], ":")
+		if len(got) != len(want) {
+			t.Errorf("%s %s: should be %v, got %v", p[0], p[1], want, got)
+			continue
+		}
+		for i := range got {
+			if RuleLine(got[i]) != RuleLine(want[i]) {
+				t.Errorf("%s %s: should be %v, got %v", p[0], p[1], want, got)
+			}
+		}
+	}
+	g.putWorkspace(w)
+}
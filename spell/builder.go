@@ -0,0 +1,120 @@
+package spell
+
+import (
+	"runtime"
+	"sync"
+)
+
+// buildBatch is how many words have their deletes made before they are
+// added to the model so the deletes waiting to be added do not use too much memory
+const buildBatch = 4096
+
+// Builder collects words and adds them to a Model all at once
+// making the deletes of the words is shared between several goroutines
+// which is most of the work of loading a dictionary
+type Builder struct {
+	// Workers is how many goroutines make deletes
+	Workers int
+
+	counts map[string]int
+	// words in the order they were added so building is repeatable
+	order []string
+}
+
+// NewBuilder returns a Builder using every CPU
+func NewBuilder() *Builder {
+	return &Builder{
+		Workers: runtime.NumCPU(),
+		counts:  make(map[string]int),
+	}
+}
+
+// Add adds n to the count of word
+func (b *Builder) Add(word string, n int) {
+	if _, ok := b.counts[word]; !ok {
+		b.order = append(b.order, word)
+	}
+	b.counts[word] += n
+}
+
+// Len returns how many different words have been added
+func (b *Builder) Len() int {
+	return len(b.order)
+}
+
+// Build adds the words to m the same as CreateEntry would and empties the Builder
+// m can not be used by anything else while it is building
+func (b *Builder) Build(m *Model) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// counts first so each word reaching the threshold is indexed once
+	// words that normalize to the same word are counted together
+	var index []string
+	for _, word := range b.order {
+		count := b.counts[word]
+		word = m.Normalize.Normalize(word)
+		if m.addCount(word, count) {
+			index = append(index, word)
+		}
+	}
+
+	// the next batch of deletes is made while the last one is added
+	batches := make(chan deleteBatch, 1)
+	go b.makeDeletes(m, index, batches)
+
+	// the map can only be written by one goroutine
+	for batch := range batches {
+		for i, word := range batch.words {
+			for _, val := range batch.deletes[i] {
+				m.addSuggestion(val, word)
+			}
+		}
+	}
+
+	b.counts = make(map[string]int)
+	b.order = nil
+}
+
+// deleteBatch is the deletes of each of a batch of words
+type deleteBatch struct {
+	words   []string
+	deletes [][]string
+}
+
+// makeDeletes makes the deletes of words in batches using the workers
+// and sends them down batches
+func (b *Builder) makeDeletes(m *Model, words []string, batches chan<- deleteBatch) {
+	defer close(batches)
+
+	workers := b.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	for start := 0; start < len(words); start += buildBatch {
+		end := start + buildBatch
+		if end > len(words) {
+			end = len(words)
+		}
+		batch := deleteBatch{
+			words:   words[start:end],
+			deletes: make([][]string, end-start),
+		}
+
+		// each goroutine makes the deletes for every workers'th word
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := w; i < len(batch.words); i += workers {
+					batch.deletes[i] = m.wordDeletes(batch.words[i])
+				}
+			}(w)
+		}
+		wg.Wait()
+
+		batches <- batch
+	}
+}
@@ -0,0 +1,93 @@
+package spell
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestBuilderMatchesCreateEntry(t *testing.T) {
+	words := bigWordlist()
+
+	sequential := NewModel()
+	for _, word := range words {
+		sequential.CreateEntry(word)
+	}
+
+	for _, workers := range []int{1, 3, 8} {
+		m := NewModel()
+		b := NewBuilder()
+		b.Workers = workers
+		for _, word := range words {
+			b.Add(word, 1)
+		}
+		b.Build(m)
+
+		if b.Len() != 0 {
+			t.Errorf("the builder should be empty after building")
+		}
+		if len(m.Data) != len(sequential.Data) {
+			t.Fatalf("%d workers: should have %d terms, got %d", workers, len(sequential.Data), len(m.Data))
+		}
+		for key, term := range sequential.Data {
+			if !reflect.DeepEqual(term, m.Data[key]) {
+				t.Errorf("%d workers: %s should be %v, got %v", workers, key, term, m.Data[key])
+			}
+		}
+	}
+}
+
+func TestBuilderThreshold(t *testing.T) {
+	m := NewModel()
+	m.Threshold = 2
+
+	b := NewBuilder()
+	b.Add("love", 1)
+	b.Add("live", 1)
+	b.Add("love", 1)
+	b.Build(m)
+
+	if !m.IsWord("love") || m.IsWord("live") {
+		t.Errorf("only love should be a word")
+	}
+
+	// live reaches the threshold in the next build
+	b.Add("live", 1)
+	b.Build(m)
+	if !m.IsWord("live") {
+		t.Errorf("live should be a word")
+	}
+	if term := m.Data["lve"]; len(term.Suggestions) != 2 {
+		t.Errorf("lve should suggest love and live once, got %v", term.Suggestions)
+	}
+}
+
+func TestConcurrentSuggestReplace(t *testing.T) {
+	m := NewModel()
+	if err := m.LoadWordList(strings.NewReader(strings.Join(bigWordlist()[:500], "\n"))); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				if i%2 == 0 {
+					m.Replace("", strings.Repeat("x", j%7+1)+"love")
+				} else {
+					m.Suggest("abd")
+					m.Segment("abcdabce")
+					m.IsWord("abcd")
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if !m.IsWord("xlove") {
+		t.Errorf("xlove should be a word")
+	}
+}
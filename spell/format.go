@@ -0,0 +1,470 @@
+package spell
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+)
+
+// The processed model format is laid out so it can be used straight from a
+// memory mapped file without decoding it first.
+//
+//	header     fileHeader
+//	words      wordCount entries of string offset, string length, 64 bit count
+//	deletes    deleteCount entries of string offset, string length, postings offset, postings length
+//	postings   postingCount word ids, the words each delete is a delete of
+//	strings    every word and delete
+//
+// words and deletes are sorted so they can be binary searched and every number
+// is a little endian uint32 except for Max and the counts
+
+// modelMagic starts every processed model
+var modelMagic = [8]byte{'M', 'M', 'S', 'P', 'E', 'L', 'L', 0}
+
+// ModelVersion is the version of the processed model format that is written
+const ModelVersion = 1
+
+// fileHeader is the start of a processed model
+type fileHeader struct {
+	Magic   [8]byte
+	Version uint32
+	// the Normalization of the model
+	Flags uint32
+
+	Threshold    uint32
+	Depth        uint32
+	PrefixLength uint32
+	Max          uint64
+
+	WordCount    uint32
+	DeleteCount  uint32
+	PostingCount uint32
+	StringsLen   uint32
+}
+
+// sizes of the parts of the file
+var headerSize = binary.Size(fileHeader{})
+
+const (
+	wordEntrySize   = 16
+	deleteEntrySize = 16
+	postingSize     = 4
+)
+
+// ErrNotModel is returned when a file is not a processed model
+var ErrNotModel = errors.New("not a processed model")
+
+// frozenIndex is a read only model kept in the processed format
+// it is safe to use from many goroutines
+type frozenIndex struct {
+	words    []byte
+	deletes  []byte
+	postings []byte
+	strings  []byte
+
+	wordCount   int
+	deleteCount int
+
+	// release unmaps the file if it was memory mapped
+	release func() error
+}
+
+// parseIndex checks the header and sizes of a processed model and returns
+// the header and index that use data directly
+func parseIndex(data []byte) (fileHeader, *frozenIndex, error) {
+	var h fileHeader
+	if len(data) < headerSize {
+		return h, nil, ErrNotModel
+	}
+	if err := binary.Read(bytes.NewReader(data[:headerSize]), binary.LittleEndian, &h); err != nil {
+		return h, nil, err
+	}
+	if h.Magic != modelMagic {
+		return h, nil, ErrNotModel
+	}
+	if h.Version != ModelVersion {
+		return h, nil, fmt.Errorf("processed model version %d is not supported, only version %d is", h.Version, ModelVersion)
+	}
+
+	f := &frozenIndex{
+		wordCount:   int(h.WordCount),
+		deleteCount: int(h.DeleteCount),
+	}
+
+	// carve the sections out of data making sure each one is there
+	rest := data[headerSize:]
+	sections := []struct {
+		section *[]byte
+		size    uint64
+	}{
+		{&f.words, uint64(h.WordCount) * wordEntrySize},
+		{&f.deletes, uint64(h.DeleteCount) * deleteEntrySize},
+		{&f.postings, uint64(h.PostingCount) * postingSize},
+		{&f.strings, uint64(h.StringsLen)},
+	}
+	for _, s := range sections {
+		if uint64(len(rest)) < s.size {
+			return h, nil, errors.New("processed model is truncated")
+		}
+		*s.section = rest[:s.size]
+		rest = rest[s.size:]
+	}
+	if len(rest) != 0 {
+		return h, nil, errors.New("processed model has trailing data")
+	}
+
+	return h, f, nil
+}
+
+// validate checks every offset in the index is inside the file and the
+// tables are sorted so nothing can read past the end of the file
+func (f *frozenIndex) validate() error {
+	for _, table := range []struct {
+		name  string
+		data  []byte
+		size  int
+		count int
+	}{
+		{"word", f.words, wordEntrySize, f.wordCount},
+		{"delete", f.deletes, deleteEntrySize, f.deleteCount},
+	} {
+		var previous []byte
+		for i := 0; i < table.count; i++ {
+			offset := entry(table.data, table.size, i, 0)
+			length := entry(table.data, table.size, i, 1)
+			if offset+length > len(f.strings) || offset+length < offset {
+				return fmt.Errorf("processed model %s %d is outside of the strings", table.name, i)
+			}
+
+			s := f.str(table.data, table.size, i)
+			if i > 0 && bytes.Compare(previous, s) >= 0 {
+				return fmt.Errorf("processed model %s %d is out of order", table.name, i)
+			}
+			previous = s
+		}
+	}
+
+	postings := len(f.postings) / postingSize
+	for i := 0; i < f.deleteCount; i++ {
+		offset := entry(f.deletes, deleteEntrySize, i, 2)
+		length := entry(f.deletes, deleteEntrySize, i, 3)
+		if offset+length > postings || offset+length < offset {
+			return fmt.Errorf("processed model delete %d is outside of the postings", i)
+		}
+	}
+	for p := 0; p < postings; p++ {
+		if id := int(binary.LittleEndian.Uint32(f.postings[p*postingSize:])); id >= f.wordCount {
+			return fmt.Errorf("processed model posting %d is not a word", p)
+		}
+	}
+
+	return nil
+}
+
+// entry reads the nth uint32 of an entry in a table
+func entry(table []byte, size, i, n int) int {
+	return int(binary.LittleEndian.Uint32(table[i*size+n*4:]))
+}
+
+// str returns the string an entry points at
+func (f *frozenIndex) str(table []byte, size, i int) []byte {
+	offset := entry(table, size, i, 0)
+	return f.strings[offset : offset+entry(table, size, i, 1)]
+}
+
+func (f *frozenIndex) word(i int) string {
+	return string(f.str(f.words, wordEntrySize, i))
+}
+
+func (f *frozenIndex) delete(i int) string {
+	return string(f.str(f.deletes, deleteEntrySize, i))
+}
+
+// size is how many bytes the index uses
+func (f *frozenIndex) size() int64 {
+	return int64(len(f.words) + len(f.deletes) + len(f.postings) + len(f.strings))
+}
+
+// find binary searches a table for key
+func (f *frozenIndex) find(table []byte, size, count int, key string) (int, bool) {
+	k := []byte(key)
+	i := sort.Search(count, func(i int) bool {
+		return bytes.Compare(f.str(table, size, i), k) >= 0
+	})
+	return i, i < count && bytes.Equal(f.str(table, size, i), k)
+}
+
+// term builds the Term for key from the index
+func (f *frozenIndex) term(key string) (*Term, bool) {
+	w, isWord := f.find(f.words, wordEntrySize, f.wordCount, key)
+	d, isDelete := f.find(f.deletes, deleteEntrySize, f.deleteCount, key)
+	if !isWord && !isDelete {
+		return nil, false
+	}
+
+	t := &Term{Suggestions: make([]string, 0)}
+	if isWord {
+		t.Count = int(binary.LittleEndian.Uint64(f.words[w*wordEntrySize+8:]))
+	}
+	if isDelete {
+		offset := entry(f.deletes, deleteEntrySize, d, 2)
+		length := entry(f.deletes, deleteEntrySize, d, 3)
+		for p := offset; p < offset+length; p++ {
+			id := int(binary.LittleEndian.Uint32(f.postings[p*postingSize:]))
+			t.Suggestions = append(t.Suggestions, f.word(id))
+		}
+	}
+	return t, true
+}
+
+// total adds up the counts of every word
+func (f *frozenIndex) total() int {
+	t := 0
+	for i := 0; i < f.wordCount; i++ {
+		t += int(binary.LittleEndian.Uint64(f.words[i*wordEntrySize+8:]))
+	}
+	return t
+}
+
+// keys returns every word and delete in the index
+func (f *frozenIndex) keys() []string {
+	keys := make([]string, 0, f.wordCount+f.deleteCount)
+	for i := 0; i < f.wordCount; i++ {
+		keys = append(keys, f.word(i))
+	}
+	for i := 0; i < f.deleteCount; i++ {
+		keys = append(keys, f.delete(i))
+	}
+	return keys
+}
+
+// WriteTo writes the model in the processed format
+func (m *Model) WriteTo(w io.Writer) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := m.keys()
+
+	// strings are shared between a word and a delete of another word
+	stringOffsets := make(map[string]uint32, len(keys))
+	var blob bytes.Buffer
+
+	var words []string
+	wordIDs := make(map[string]uint32)
+	var deletes []string
+	terms := make(map[string]*Term, len(keys))
+	for _, key := range keys {
+		t, _ := m.term(key)
+		terms[key] = t
+		stringOffsets[key] = uint32(blob.Len())
+		blob.WriteString(key)
+
+		if t.Count > 0 {
+			wordIDs[key] = uint32(len(words))
+			words = append(words, key)
+		}
+		if len(t.Suggestions) > 0 {
+			deletes = append(deletes, key)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	le := binary.LittleEndian
+	put := func(v uint32) {
+		var b [4]byte
+		le.PutUint32(b[:], v)
+		buf.Write(b[:])
+	}
+
+	for _, word := range words {
+		put(stringOffsets[word])
+		put(uint32(len(word)))
+		var b [8]byte
+		le.PutUint64(b[:], uint64(terms[word].Count))
+		buf.Write(b[:])
+	}
+
+	var postings []uint32
+	for _, d := range deletes {
+		put(stringOffsets[d])
+		put(uint32(len(d)))
+		put(uint32(len(postings)))
+
+		// a suggestion must be a word to be written
+		n := 0
+		for _, s := range terms[d].Suggestions {
+			if id, ok := wordIDs[s]; ok {
+				postings = append(postings, id)
+				n++
+			}
+		}
+		put(uint32(n))
+	}
+	for _, id := range postings {
+		put(id)
+	}
+
+	h := fileHeader{
+		Magic:        modelMagic,
+		Version:      ModelVersion,
+		Flags:        uint32(m.Normalize),
+		Threshold:    uint32(m.Threshold),
+		Depth:        uint32(m.Depth),
+		PrefixLength: uint32(m.PrefixLength),
+		Max:          uint64(m.Max),
+		WordCount:    uint32(len(words)),
+		DeleteCount:  uint32(len(deletes)),
+		PostingCount: uint32(len(postings)),
+		StringsLen:   uint32(blob.Len()),
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, h); err != nil {
+		return 0, err
+	}
+	n, err := buf.WriteTo(w)
+	if err != nil {
+		return int64(headerSize) + n, err
+	}
+	b, err := blob.WriteTo(w)
+	return int64(headerSize) + n + b, err
+}
+
+// ReadModel reads a model in the processed format into memory
+func ReadModel(r io.Reader) (*Model, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return newFrozenModel(data, nil)
+}
+
+// OpenModel opens a processed model
+// the file is memory mapped read only where possible so the model can be
+// shared between goroutines and processes without loading it
+// models saved with gob are loaded into memory
+// Close releases the file
+func OpenModel(name string) (*Model, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	magic := make([]byte, len(modelMagic))
+	if _, err := io.ReadFull(file, magic); err != nil || !bytes.Equal(magic, modelMagic[:]) {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return loadGob(file)
+	}
+
+	data, release, err := mapFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := newFrozenModel(data, release)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return m, nil
+}
+
+// newFrozenModel makes a model backed by data in the processed format
+func newFrozenModel(data []byte, release func() error) (*Model, error) {
+	h, f, err := parseIndex(data)
+	if err != nil {
+		return nil, err
+	}
+	f.release = release
+
+	m := NewModel()
+	m.Threshold = int(h.Threshold)
+	m.Depth = int(h.Depth)
+	m.PrefixLength = int(h.PrefixLength)
+	m.Normalize = Normalization(h.Flags)
+	m.Max = int(h.Max)
+	m.index = f
+	m.Total = f.total()
+
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// loadGob loads a model saved with gob by older versions
+// the first versions kept a count of 1 for every delete so a delete looked
+// like a word, the words are found again and indexed with DefaultOptions
+func loadGob(r io.Reader) (*Model, error) {
+	var old struct {
+		Data      map[string]*Term
+		Threshold int
+	}
+	if err := gob.NewDecoder(r).Decode(&old); err != nil {
+		return nil, err
+	}
+
+	m := NewModel()
+	if old.Threshold > 0 {
+		m.Threshold = old.Threshold
+	}
+
+	// a word is suggested by its deletes and a word that was never indexed
+	// suggests nothing, a delete only suggests words
+	counts := make(map[string]int)
+	for key, term := range old.Data {
+		if len(term.Suggestions) == 0 && term.Count > 0 {
+			counts[key] = term.Count
+		}
+	}
+	for _, term := range old.Data {
+		for _, word := range term.Suggestions {
+			// a word that was also a delete lost its count so it is given
+			// enough to be a word again
+			n := m.Threshold
+			if t, ok := old.Data[word]; ok && t.Count > n {
+				n = t.Count
+			}
+			counts[word] = n
+		}
+	}
+
+	words := make([]string, 0, len(counts))
+	for word := range counts {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+
+	b := NewBuilder()
+	for _, word := range words {
+		b.Add(word, counts[word])
+	}
+	b.Build(m)
+
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Close releases the file backing the model if there is one
+func (m *Model) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.index == nil || m.index.release == nil {
+		return nil
+	}
+	// the model can not be used after the file is unmapped
+	err := m.index.release()
+	m.index = nil
+	return err
+}
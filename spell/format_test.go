@@ -0,0 +1,312 @@
+package spell
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const formatWords = "love\nlove\nlove\nlive\nlover\nglove\nglove\nbattery\npassword\ncafé\n"
+
+// lookups that are checked against every way of loading a model
+var formatLookups = []string{"lve", "love", "batetry", "pasword", "cafe", "zzzz"}
+
+// checkSameModel checks that two models have the same settings, words,
+// counts and suggestions
+func checkSameModel(t *testing.T, expected, m *Model) {
+	t.Helper()
+
+	for _, word := range formatLookups {
+		e := expected.Lookup(word, All, 2)
+		out := m.Lookup(word, All, 2)
+		if !reflect.DeepEqual(e, out) {
+			t.Errorf("%s: should be %v, got %v", word, e, out)
+		}
+	}
+	if m.Threshold != expected.Threshold || m.Depth != expected.Depth || m.Max != expected.Max {
+		t.Errorf("settings should be %d %d %d, got %d %d %d",
+			expected.Threshold, expected.Depth, expected.Max, m.Threshold, m.Depth, m.Max)
+	}
+
+	e, out := modelTerms(expected), modelTerms(m)
+	if len(e) != len(out) {
+		t.Errorf("should have %d terms, got %d", len(e), len(out))
+	}
+	for key, term := range e {
+		if !reflect.DeepEqual(term, out[key]) {
+			t.Errorf("%s should be %v, got %v", key, term, out[key])
+		}
+	}
+	if expected.Total != m.Total {
+		t.Errorf("total should be %d, got %d", expected.Total, m.Total)
+	}
+}
+
+// modelTerms returns every term of m that is a word or suggests one
+// with its suggestions sorted
+func modelTerms(m *Model) map[string]Term {
+	out := make(map[string]Term)
+	for _, key := range m.keys() {
+		term, _ := m.term(key)
+		if term.Count == 0 && len(term.Suggestions) == 0 {
+			continue
+		}
+		suggestions := append([]string{}, term.Suggestions...)
+		sort.Strings(suggestions)
+		out[key] = Term{term.Count, suggestions}
+	}
+	return out
+}
+
+func TestWriteReadModel(t *testing.T) {
+	m := NewModel()
+	m.LoadWordList(strings.NewReader(formatWords))
+
+	var buf bytes.Buffer
+	if _, err := m.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := ReadModel(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSameModel(t, m, loaded)
+
+	if !loaded.IsWord("love") || loaded.IsWord("lov") {
+		t.Errorf("love should be a word and lov should not")
+	}
+	if term, _ := loaded.term("love"); term.Count != 3 {
+		t.Errorf("love count should be 3, got %d", term.Count)
+	}
+}
+
+func TestOpenModel(t *testing.T) {
+	m := NewModel()
+	m.LoadWordList(strings.NewReader(formatWords))
+
+	dir, err := ioutil.TempDir("", "spell")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "words.processed")
+	file, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SaveWordList(file); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	opened, err := OpenModel(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer opened.Close()
+	checkSameModel(t, m, opened)
+
+	// gob models from older versions
+	gobName := filepath.Join(dir, "words.gob")
+	file, err = os.Create(gobName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gob.NewEncoder(file).Encode(m); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	legacy, err := OpenModel(gobName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSameModel(t, m, legacy)
+}
+
+func TestLoadSavedWordListGob(t *testing.T) {
+	m := NewModel()
+	m.LoadWordList(strings.NewReader(formatWords))
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(m); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadSavedWordList(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSameModel(t, m, loaded)
+}
+
+func TestLoadOldGob(t *testing.T) {
+	// the model as the first versions saved it, every delete of a word
+	// replaced whatever was there with a count of 1 suggesting the word
+	old := struct {
+		Data      map[string]*Term
+		Threshold int
+		Depth     int
+		Max       int
+	}{Data: make(map[string]*Term), Threshold: 1, Depth: 100}
+	for _, word := range strings.Fields(formatWords) {
+		if term, ok := old.Data[word]; ok {
+			term.Count++
+			continue
+		}
+		old.Data[word] = &Term{1, []string{}}
+		for _, d := range Edits([]rune(word), 0, old.Depth) {
+			old.Data[d] = &Term{1, []string{word}}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(old); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadSavedWordList(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the words are indexed again the way a new model is
+	if loaded.Depth != DefaultOptions().MaxEditDistance || loaded.PrefixLength != DefaultOptions().PrefixLength {
+		t.Errorf("should be indexed with the default options, got depth %d prefix length %d", loaded.Depth, loaded.PrefixLength)
+	}
+	// love is lost because every one of its deletes was replaced by the
+	// deletes of lover and glove
+	for _, word := range []string{"live", "lover", "glove", "battery", "password", "café"} {
+		if !loaded.IsWord(word) {
+			t.Errorf("%s should be a word", word)
+		}
+	}
+	// deletes are not words
+	for _, word := range []string{"pswd", "lve", "batt", "ove"} {
+		if loaded.IsWord(word) {
+			t.Errorf("%s should not be a word", word)
+		}
+		if out := loaded.Suggest(word); contains(out, word) {
+			t.Errorf("%s should not be suggested, got %v", word, out)
+		}
+	}
+	if out := loaded.Suggest("pasword"); strings.Join(out, " ") != "password" {
+		t.Errorf("pasword: should suggest password, got %v", out)
+	}
+	// the counts of words that were not deletes are kept
+	if out := loaded.Lookup("battery", Top, 0); len(out) != 1 || out[0].Count != 1 {
+		t.Errorf("battery: should have a count of 1, got %v", out)
+	}
+	if out := loaded.Lookup("glove", Top, 0); len(out) != 1 || out[0].Count != 2 {
+		t.Errorf("glove: should have a count of 2, got %v", out)
+	}
+}
+
+func TestFrozenModelChanges(t *testing.T) {
+	m := NewModel()
+	m.LoadWordList(strings.NewReader(formatWords))
+
+	var buf bytes.Buffer
+	if err := m.SaveWordList(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadSavedWordList(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// words added after loading go on top of the processed model
+	m.CreateEntry("love")
+	m.CreateEntry("lovely")
+	loaded.CreateEntry("love")
+	loaded.CreateEntry("lovely")
+	checkSameModel(t, m, loaded)
+
+	// and are saved with it
+	buf.Reset()
+	if err := loaded.SaveWordList(&buf); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := LoadSavedWordList(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSameModel(t, m, saved)
+}
+
+func TestReadModelErrors(t *testing.T) {
+	m := NewModel()
+	m.LoadWordList(strings.NewReader(formatWords))
+
+	var buf bytes.Buffer
+	m.WriteTo(&buf)
+	data := buf.Bytes()
+
+	if _, err := ReadModel(bytes.NewReader([]byte("not a model at all, not even close"))); err != ErrNotModel {
+		t.Errorf("should be ErrNotModel, got %v", err)
+	}
+
+	if _, err := ReadModel(bytes.NewReader(data[:len(data)-1])); err == nil {
+		t.Errorf("a truncated model should fail")
+	}
+
+	newer := append([]byte(nil), data...)
+	newer[len(modelMagic)] = ModelVersion + 1
+	if _, err := ReadModel(bytes.NewReader(newer)); err == nil {
+		t.Errorf("a newer version should fail")
+	}
+
+	// the first word points past the end of the strings
+	corrupt := append([]byte(nil), data...)
+	corrupt[headerSize] = 0xff
+	corrupt[headerSize+1] = 0xff
+	if _, err := ReadModel(bytes.NewReader(corrupt)); err == nil {
+		t.Errorf("a corrupt model should fail")
+	}
+
+	if _, err := LoadSavedWordList(bytes.NewReader([]byte("garbage"))); err == nil {
+		t.Errorf("garbage should fail")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	m := NewModel()
+	if err := m.Validate(); err == nil {
+		t.Errorf("a model without words should fail")
+	}
+
+	m.LoadWordList(strings.NewReader(formatWords))
+	if err := m.Validate(); err != nil {
+		t.Errorf("should be valid, got %v", err)
+	}
+
+	m.Data["lov"].Suggestions = append(m.Data["lov"].Suggestions, "nothere")
+	if err := m.Validate(); err == nil {
+		t.Errorf("a suggestion that is not a word should fail")
+	}
+}
+
+func TestWriteReadLargeCount(t *testing.T) {
+	m := NewModel()
+	if err := m.LoadFrequencyList(strings.NewReader("the 23135851162\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := m.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := ReadModel(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if term, _ := loaded.term("the"); term.Count != 23135851162 {
+		t.Errorf("the count should be 23135851162, got %d", term.Count)
+	}
+}
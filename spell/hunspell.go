@@ -0,0 +1,444 @@
+package spell
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding/ianaindex"
+)
+
+// Hunspell dictionaries are made of a .dic file holding the words with flags
+// naming the affixes that can be added to them and an .aff file describing the
+// affixes. https://linux.die.net/man/4/hunspell
+
+// affix is a single prefix or suffix rule from the .aff file
+type affix struct {
+	// characters removed from the word before adding
+	strip string
+	// characters added to the word
+	add string
+	// flags of affixes that can be added after this one
+	flags []string
+	// condition the word must match for the affix to be applied
+	condition *regexp.Regexp
+}
+
+// affixClass is all of the rules sharing a flag
+type affixClass struct {
+	prefix       bool
+	crossProduct bool
+	rules        []affix
+}
+
+// apply adds the affix to the word returning false when it does not apply
+func (a affix) apply(word string, prefix bool) (string, bool) {
+	if prefix {
+		if !strings.HasPrefix(word, a.strip) || !a.condition.MatchString(word) {
+			return "", false
+		}
+		return a.add + word[len(a.strip):], true
+	}
+
+	if !strings.HasSuffix(word, a.strip) || !a.condition.MatchString(word) {
+		return "", false
+	}
+	return word[:len(word)-len(a.strip)] + a.add, true
+}
+
+// hunspellAffixes holds what is needed from an .aff file to expand words
+type hunspellAffixes struct {
+	encoding string
+	flagType string
+	// flag aliases from AF
+	aliases [][]string
+
+	classes map[string]*affixClass
+
+	// words with these flags are not words on their own
+	needAffix      string
+	onlyInCompound string
+	forbidden      string
+}
+
+// parseHunspellAffixes reads an .aff file
+func parseHunspellAffixes(aff io.Reader) (*hunspellAffixes, error) {
+	data, err := ioutil.ReadAll(aff)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &hunspellAffixes{
+		encoding: "UTF-8",
+		classes:  make(map[string]*affixClass),
+	}
+
+	// the encoding and flag type are needed before anything else can be read
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "SET":
+			h.encoding = fields[1]
+		case "FLAG":
+			h.flagType = fields[1]
+		}
+	}
+
+	text, err := decodeHunspell(data, h.encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	lineNumber := 0
+	// the first AF line is the count of aliases and the rest are aliases
+	aliasCount := false
+	for scanner.Scan() {
+		lineNumber++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		switch fields[0] {
+		case "AF":
+			if !aliasCount {
+				aliasCount = true
+				continue
+			}
+			h.aliases = append(h.aliases, h.parseFlags(fields[1]))
+		case "NEEDAFFIX", "PSEUDOROOT":
+			h.needAffix = fields[1]
+		case "ONLYINCOMPOUND":
+			h.onlyInCompound = fields[1]
+		case "FORBIDDENWORD":
+			h.forbidden = fields[1]
+		case "PFX", "SFX":
+			if err := h.parseAffix(fields); err != nil {
+				return nil, fmt.Errorf("affix file line %d: %v", lineNumber, err)
+			}
+		}
+	}
+
+	return h, scanner.Err()
+}
+
+// parseAffix reads a PFX or SFX header or rule line
+func (h *hunspellAffixes) parseAffix(fields []string) error {
+	flag := fields[1]
+	class, ok := h.classes[flag]
+
+	// header: PFX flag cross_product count
+	if !ok {
+		if len(fields) < 4 {
+			return errors.New("affix header is too short")
+		}
+		h.classes[flag] = &affixClass{
+			prefix:       fields[0] == "PFX",
+			crossProduct: fields[2] == "Y",
+		}
+		return nil
+	}
+
+	// rule: PFX flag stripping prefix[/flags] [condition [morphological fields...]]
+	if len(fields) < 4 {
+		return errors.New("affix rule is too short")
+	}
+
+	a := affix{strip: fields[2]}
+	if a.strip == "0" {
+		a.strip = ""
+	}
+
+	add := fields[3]
+	if i := strings.Index(add, "/"); i >= 0 {
+		a.flags = h.parseFlags(add[i+1:])
+		add = add[:i]
+	}
+	if add != "0" {
+		a.add = add
+	}
+
+	condition := "."
+	if len(fields) > 4 {
+		condition = fields[4]
+	}
+
+	var err error
+	a.condition, err = compileCondition(condition, class.prefix)
+	if err != nil {
+		return err
+	}
+
+	class.rules = append(class.rules, a)
+	return nil
+}
+
+// compileCondition turns an affix condition into a regexp matching the start
+// of the word for prefixes and the end of the word for suffixes
+// conditions only use characters, '.' and [] or [^] character classes
+func compileCondition(condition string, prefix bool) (*regexp.Regexp, error) {
+	var pattern bytes.Buffer
+	inClass := false
+	for _, r := range condition {
+		switch {
+		case r == '[':
+			inClass = true
+			pattern.WriteRune(r)
+		case r == ']':
+			inClass = false
+			pattern.WriteRune(r)
+		case r == '^' && inClass:
+			pattern.WriteRune(r)
+		case r == '.' && !inClass:
+			pattern.WriteRune(r)
+		default:
+			pattern.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	if prefix {
+		return regexp.Compile("^(?:" + pattern.String() + ")")
+	}
+	return regexp.Compile("(?:" + pattern.String() + ")$")
+}
+
+// parseFlags splits the flags of a word or affix based on the flag type
+func (h *hunspellAffixes) parseFlags(flags string) []string {
+	// flags can be an alias number from AF
+	if len(h.aliases) > 0 {
+		if n, err := strconv.Atoi(flags); err == nil && n > 0 && n <= len(h.aliases) {
+			return h.aliases[n-1]
+		}
+	}
+
+	var split []string
+	switch h.flagType {
+	case "long":
+		runes := []rune(flags)
+		for i := 0; i+1 < len(runes); i += 2 {
+			split = append(split, string(runes[i:i+2]))
+		}
+	case "num":
+		for _, flag := range strings.Split(flags, ",") {
+			if len(flag) > 0 {
+				split = append(split, flag)
+			}
+		}
+	default:
+		// single characters or UTF-8 characters
+		for _, r := range flags {
+			split = append(split, string(r))
+		}
+	}
+	return split
+}
+
+// hasFlag checks if flag is in flags
+func hasFlag(flags []string, flag string) bool {
+	if len(flag) == 0 {
+		return false
+	}
+	for _, f := range flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// expand returns the word and every form of it made by its affixes
+func (h *hunspellAffixes) expand(word string, flags []string) []string {
+	if hasFlag(flags, h.forbidden) || hasFlag(flags, h.onlyInCompound) {
+		return nil
+	}
+
+	var forms []string
+	if !hasFlag(flags, h.needAffix) {
+		forms = append(forms, word)
+	}
+
+	var prefixed []string
+	var suffixed []string
+	for _, flag := range flags {
+		class, ok := h.classes[flag]
+		if !ok {
+			continue
+		}
+
+		for _, a := range class.rules {
+			form, ok := a.apply(word, class.prefix)
+			if !ok {
+				continue
+			}
+
+			if !hasFlag(a.flags, h.needAffix) {
+				forms = append(forms, form)
+			}
+
+			// twofold affixes only one more level is applied
+			for _, next := range h.applyFlags(form, a.flags) {
+				forms = append(forms, next)
+			}
+
+			if class.crossProduct {
+				if class.prefix {
+					prefixed = append(prefixed, form)
+				} else {
+					suffixed = append(suffixed, form)
+				}
+			}
+		}
+	}
+
+	// cross products add a prefix to every suffixed form
+	if len(prefixed) > 0 && len(suffixed) > 0 {
+		for _, form := range suffixed {
+			for _, flag := range flags {
+				class, ok := h.classes[flag]
+				if !ok || !class.prefix || !class.crossProduct {
+					continue
+				}
+				for _, a := range class.rules {
+					if cross, ok := a.apply(form, true); ok {
+						forms = append(forms, cross)
+					}
+				}
+			}
+		}
+	}
+
+	// the same form can be made by more than one affix
+	unique := forms[:0]
+	seen := NewHash()
+	for _, form := range forms {
+		if seen.Add(form) {
+			unique = append(unique, form)
+		}
+	}
+
+	return unique
+}
+
+// applyFlags applies the affixes named by flags to word
+func (h *hunspellAffixes) applyFlags(word string, flags []string) []string {
+	var forms []string
+	for _, flag := range flags {
+		class, ok := h.classes[flag]
+		if !ok {
+			continue
+		}
+		for _, a := range class.rules {
+			if form, ok := a.apply(word, class.prefix); ok {
+				forms = append(forms, form)
+			}
+		}
+	}
+	return forms
+}
+
+// splitHunspellWord splits a .dic line into the word and its flags
+func (h *hunspellAffixes) splitHunspellWord(line string) (string, []string) {
+	// morphological fields are after a tab or a space
+	if i := strings.IndexAny(line, "\t "); i >= 0 {
+		line = line[:i]
+	}
+
+	// a / in a word is escaped with \/
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if line[i] == '/' {
+			word := strings.Replace(line[:i], `\/`, "/", -1)
+			return word, h.parseFlags(line[i+1:])
+		}
+	}
+
+	return strings.Replace(line, `\/`, "/", -1), nil
+}
+
+// decodeHunspell converts the text of a dictionary in the SET encoding to UTF-8
+func decodeHunspell(data []byte, encoding string) (string, error) {
+	name := strings.ToUpper(encoding)
+	switch {
+	case name == "UTF-8" || name == "UTF8":
+		return string(data), nil
+	case strings.HasPrefix(name, "ISO8859-"):
+		name = "ISO-8859-" + strings.TrimPrefix(name, "ISO8859-")
+	case strings.HasPrefix(name, "MICROSOFT-CP"):
+		name = "windows-" + strings.TrimPrefix(name, "MICROSOFT-CP")
+	}
+
+	enc, err := ianaindex.IANA.Encoding(name)
+	if err != nil || enc == nil {
+		return "", fmt.Errorf("unsupported dictionary encoding %q", encoding)
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", err
+	}
+	return string(decoded), nil
+}
+
+// LoadHunspell loads a Hunspell dictionary into the Model
+// every word in the .dic file is expanded with the affixes from the .aff file
+// and each form is added to the model
+func (m *Model) LoadHunspell(dic, aff io.Reader) error {
+	h, err := parseHunspellAffixes(aff)
+	if err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadAll(dic)
+	if err != nil {
+		return err
+	}
+
+	text, err := decodeHunspell(data, h.encoding)
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	b := NewBuilder()
+	first := true
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		// the first line is the approximate count of words
+		if first {
+			first = false
+			if _, err := strconv.Atoi(line); err == nil {
+				continue
+			}
+		}
+
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		word, flags := h.splitHunspellWord(line)
+		for _, form := range h.expand(word, flags) {
+			b.Add(form, 1)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	b.Build(m)
+	return nil
+}
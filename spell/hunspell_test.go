@@ -0,0 +1,151 @@
+package spell
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+const testAff = `SET UTF-8
+TRY esianrtolcdugmphbyfvkwz
+
+NEEDAFFIX X
+
+PFX A Y 1
+PFX A   0     re         .
+
+SFX D Y 4
+SFX D   0     d          e
+SFX D   y     ied        [^aeiou]y
+SFX D   0     ed         [^ey]
+SFX D   0     ed         [aeiou]y
+
+SFX S Y 2
+SFX S   y     ies        [^aeiou]y
+SFX S   0     s          [^y]
+
+SFX M N 1
+SFX M   0     's         .
+`
+
+const testDic = `5
+create/ADS
+try/DS
+cat/MS
+work\/flow
+stem/XS
+`
+
+func TestLoadHunspell(t *testing.T) {
+	m := NewModel()
+	if err := m.LoadHunspell(strings.NewReader(testDic), strings.NewReader(testAff)); err != nil {
+		t.Fatal(err)
+	}
+
+	words := []string{
+		"create", "created", "creates", "recreate", "recreated", "recreates",
+		"try", "tried", "tries",
+		"cat", "cat's", "cats",
+		"work/flow",
+		"stems",
+	}
+	for _, word := range words {
+		if term, ok := m.Data[word]; !ok || term.Count == 0 {
+			t.Errorf("%s should be a word", word)
+		}
+	}
+}
+
+func TestExpandCrossProduct(t *testing.T) {
+	h, err := parseHunspellAffixes(strings.NewReader(testAff))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	word, flags := h.splitHunspellWord("create/AD")
+	forms := h.expand(word, flags)
+	sort.Strings(forms)
+
+	expected := []string{"create", "created", "recreate", "recreated"}
+	if strings.Join(forms, " ") != strings.Join(expected, " ") {
+		t.Errorf("should be %v, got %v", expected, forms)
+	}
+}
+
+func TestExpandNeedAffix(t *testing.T) {
+	h, err := parseHunspellAffixes(strings.NewReader(testAff))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// stem needs an affix to be a word
+	word, flags := h.splitHunspellWord("stem/XS")
+	forms := h.expand(word, flags)
+	if strings.Join(forms, " ") != "stems" {
+		t.Errorf("should be [stems], got %v", forms)
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	var flags = []struct {
+		flagType string
+		in       string
+		out      []string
+	}{
+		{"", "ADS", []string{"A", "D", "S"}},
+		{"UTF-8", "ÄÖ", []string{"Ä", "Ö"}},
+		{"long", "AaBbCc", []string{"Aa", "Bb", "Cc"}},
+		{"num", "1,22,333", []string{"1", "22", "333"}},
+	}
+
+	for _, f := range flags {
+		h := &hunspellAffixes{flagType: f.flagType}
+		out := h.parseFlags(f.in)
+		if strings.Join(out, " ") != strings.Join(f.out, " ") {
+			t.Errorf("%s %s: should be %v, got %v", f.flagType, f.in, f.out, out)
+		}
+	}
+}
+
+func TestParseFlagsAlias(t *testing.T) {
+	h, err := parseHunspellAffixes(strings.NewReader("AF 2\nAF AD\nAF S\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out := h.parseFlags("1"); strings.Join(out, " ") != "A D" {
+		t.Errorf("alias 1 should be [A D], got %v", out)
+	}
+	if out := h.parseFlags("2"); strings.Join(out, " ") != "S" {
+		t.Errorf("alias 2 should be [S], got %v", out)
+	}
+}
+
+func TestParseFlagsNumAlias(t *testing.T) {
+	// the first alias is a single number like the count before it
+	h, err := parseHunspellAffixes(strings.NewReader("FLAG num\nAF 2\nAF 101\nAF 5,6\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out := h.parseFlags("1"); strings.Join(out, " ") != "101" {
+		t.Errorf("alias 1 should be [101], got %v", out)
+	}
+	if out := h.parseFlags("2"); strings.Join(out, " ") != "5 6" {
+		t.Errorf("alias 2 should be [5 6], got %v", out)
+	}
+}
+
+func TestDecodeHunspell(t *testing.T) {
+	text, err := decodeHunspell([]byte{'c', 'a', 'f', 0xe9}, "ISO8859-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "café" {
+		t.Errorf("should be café, got %s", text)
+	}
+
+	if _, err := decodeHunspell([]byte("x"), "NOT-AN-ENCODING"); err == nil {
+		t.Errorf("should fail on an unknown encoding")
+	}
+}
@@ -0,0 +1,36 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package spell
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// mapFile memory maps a file read only
+// the mapping is shared so processes using the same model share the memory
+func mapFile(file *os.File) ([]byte, func() error, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	size := info.Size()
+	if size == 0 {
+		return nil, nil, errors.New("processed model is empty")
+	}
+	if int64(int(size)) != size {
+		return nil, nil, errors.New("processed model is too large to map")
+	}
+
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, func() error {
+		return syscall.Munmap(data)
+	}, nil
+}
@@ -0,0 +1,130 @@
+package spell
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalization is how words are changed before they are added to a Model or
+// looked up in it so the same word written different ways is found
+// several can be combined
+type Normalization uint32
+
+const (
+	// NFC composes letters and accents written separately
+	NFC Normalization = 1 << iota
+	// NFKC is NFC that also turns compatibility characters such as full width
+	// letters and ligatures into the plain characters
+	NFKC
+	// FoldCase makes words lower case for every language
+	FoldCase
+	// StripDiacritics removes accents so café is cafe
+	StripDiacritics
+)
+
+// normalizationNames are the names used by ParseNormalization and String
+var normalizationNames = []struct {
+	n    Normalization
+	name string
+}{
+	{NFC, "nfc"},
+	{NFKC, "nfkc"},
+	{FoldCase, "fold"},
+	{StripDiacritics, "strip"},
+}
+
+// allNormalizations is every known Normalization
+const allNormalizations = NFC | NFKC | FoldCase | StripDiacritics
+
+// ParseNormalization parses names separated by commas such as "nfkc,fold,strip"
+// "" and "none" are no normalization
+func ParseNormalization(s string) (Normalization, error) {
+	var n Normalization
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || name == "none" {
+			continue
+		}
+
+		found := false
+		for _, v := range normalizationNames {
+			if v.name == name {
+				n |= v.n
+				found = true
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("unknown normalization %q, normalizations are nfc, nfkc, fold and strip", name)
+		}
+	}
+	return n, n.validate()
+}
+
+// validate checks the normalizations can be used together
+func (n Normalization) validate() error {
+	if n&^allNormalizations != 0 {
+		return fmt.Errorf("unknown normalization %#x", uint32(n&^allNormalizations))
+	}
+	if n&NFC != 0 && n&NFKC != 0 {
+		return fmt.Errorf("nfc and nfkc can not both be used")
+	}
+	return nil
+}
+
+func (n Normalization) String() string {
+	var names []string
+	for _, v := range normalizationNames {
+		if n&v.n != 0 {
+			names = append(names, v.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
+
+// Set parses s into n so a Normalization can be a flag
+func (n *Normalization) Set(s string) error {
+	v, err := ParseNormalization(s)
+	if err != nil {
+		return err
+	}
+	*n = v
+	return nil
+}
+
+// Normalize returns s normalized
+// the normal form is applied first then the case is folded and then the
+// diacritics are removed
+func (n Normalization) Normalize(s string) string {
+	if n == 0 {
+		return s
+	}
+
+	if n&NFKC != 0 {
+		s = norm.NFKC.String(s)
+	} else if n&NFC != 0 {
+		s = norm.NFC.String(s)
+	}
+
+	// casers and transformers keep state so each call makes its own
+	if n&FoldCase != 0 {
+		s = cases.Fold().String(s)
+	}
+
+	if n&StripDiacritics != 0 {
+		strip := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+		if stripped, _, err := transform.String(strip, s); err == nil {
+			s = stripped
+		}
+	}
+
+	return s
+}
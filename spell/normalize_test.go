@@ -0,0 +1,126 @@
+package spell
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseNormalization(t *testing.T) {
+	var parse = []struct {
+		in  string
+		out Normalization
+		err bool
+	}{
+		{"", 0, false},
+		{"none", 0, false},
+		{"nfc", NFC, false},
+		{"NFKC, fold,strip", NFKC | FoldCase | StripDiacritics, false},
+		{"nfc,nfkc", 0, true},
+		{"upper", 0, true},
+	}
+
+	for _, test := range parse {
+		out, err := ParseNormalization(test.in)
+		if (err != nil) != test.err {
+			t.Errorf("%q: error should be %v, got %v", test.in, test.err, err)
+			continue
+		}
+		if !test.err && out != test.out {
+			t.Errorf("%q: should be %v, got %v", test.in, test.out, out)
+		}
+	}
+
+	if s := (NFKC | StripDiacritics).String(); s != "nfkc,strip" {
+		t.Errorf("should be nfkc,strip, got %s", s)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	var normalize = []struct {
+		n       Normalization
+		in, out string
+	}{
+		{0, "Cafe\u0301", "Cafe\u0301"},
+		// e and a combining accent become é
+		{NFC, "Cafe\u0301", "Caf\u00e9"},
+		// full width letters and the fi ligature
+		{NFC, "ｐａｓｓ", "ｐａｓｓ"},
+		{NFKC, "ｐａｓｓ", "pass"},
+		{NFKC, "ﬁsh", "fish"},
+		{FoldCase, "PaSSWORD", "password"},
+		{FoldCase, "Straße", "strasse"},
+		{StripDiacritics, "Crème Brûlée", "Creme Brulee"},
+		{StripDiacritics, "Cafe\u0301", "Cafe"},
+		{NFKC | FoldCase | StripDiacritics, "ＣＡＦＥ\u0301", "cafe"},
+	}
+
+	for _, test := range normalize {
+		if out := test.n.Normalize(test.in); out != test.out {
+			t.Errorf("%v %q: should be %q, got %q", test.n, test.in, test.out, out)
+		}
+	}
+}
+
+func TestNormalizedModel(t *testing.T) {
+	m := NewModelWithOptions(Options{MaxEditDistance: 2, Normalize: NFKC | FoldCase | StripDiacritics})
+	if err := m.LoadWordList(strings.NewReader("Café\ncafe\nNaïve\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// café and cafe are the same word
+	if term := m.Data["cafe"]; term == nil || term.Count != 2 {
+		t.Errorf("cafe should have a count of 2, got %v", term)
+	}
+	if _, ok := m.Data["Café"]; ok {
+		t.Errorf("Café should be normalized")
+	}
+
+	check := func(m *Model) {
+		for _, in := range []string{"CAFÉ", "ｃａｆｅ", "café", "naive"} {
+			out := m.Lookup(in, Top, 2)
+			if len(out) != 1 || out[0].Distance != 0 {
+				t.Errorf("%s: should be found, got %v", in, out)
+			}
+		}
+		if !m.IsWord("NAÏVE") {
+			t.Errorf("NAÏVE should be a word")
+		}
+	}
+	check(m)
+
+	// the normalization is saved with the model
+	var buf bytes.Buffer
+	if err := m.SaveWordList(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadSavedWordList(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Normalize != m.Normalize {
+		t.Errorf("normalization should be %v, got %v", m.Normalize, loaded.Normalize)
+	}
+	check(loaded)
+
+	// words added later are normalized too
+	loaded.Add("Crème", 1)
+	if !loaded.IsWord("creme") {
+		t.Errorf("creme should be a word")
+	}
+	if !loaded.Remove("CRÈME") || loaded.IsWord("creme") {
+		t.Errorf("creme should be removed")
+	}
+}
+
+func TestValidateNormalization(t *testing.T) {
+	m := NewModelWithOptions(Options{MaxEditDistance: 2, Normalize: NFC | NFKC})
+	m.CreateEntry("love")
+	if err := m.Validate(); err == nil {
+		t.Errorf("nfc and nfkc should fail")
+	}
+	m.Normalize = 1 << 10
+	if err := m.Validate(); err == nil {
+		t.Errorf("an unknown normalization should fail")
+	}
+}
@@ -0,0 +1,102 @@
+package spell
+
+import (
+	"math"
+	"strings"
+)
+
+// Segmenter splits text made of words without spaces into the words
+type Segmenter interface {
+	Segment(string) string
+}
+
+// segmentLength is the longest word Segment looks for
+const segmentLength = 24
+
+// Segmentation is the best way found to split text into words
+type Segmentation struct {
+	// the text with spaces between the words
+	Segmented string `json:"segmented"`
+	// the words spelling corrected
+	Corrected string `json:"corrected"`
+	// edits to get from the text to Corrected counting each space
+	Distance int `json:"distance"`
+	// log10 of the probability of the words
+	Probability float64 `json:"probability"`
+}
+
+// Segment splits text into the most likely words
+// the text is not spelling corrected so the words joined together are the
+// text normalized the same as the model
+func (m *Model) Segment(text string) string {
+	return m.WordSegmentation(text, 0, segmentLength).Segmented
+}
+
+// WordSegmentation splits input into words and corrects their spelling
+// words can be up to maxSegmentLength long and maxEditDistance from a word
+// in the dictionary
+// the split with the fewest edits is used and the one with the most probable
+// words when there is a tie, words are more probable the higher their count
+// this is symspell's WordSegmentation
+func (m *Model) WordSegmentation(input string, maxEditDistance, maxSegmentLength int) Segmentation {
+	// spaces already there are not kept
+	runes := []rune(strings.Replace(m.Normalize.Normalize(input), " ", "", -1))
+	if len(runes) == 0 {
+		return Segmentation{}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	total := float64(m.Total)
+	if total < 1 {
+		total = 1
+	}
+
+	// best[j] is the best segmentation of runes[:j]
+	best := make([]Segmentation, len(runes)+1)
+	found := make([]bool, len(runes)+1)
+	found[0] = true
+
+	for j := 0; j < len(runes); j++ {
+		// a single character can always be a word so every j is found
+		for i := 1; i <= maxSegmentLength && j+i <= len(runes); i++ {
+			part := string(runes[j : j+i])
+
+			corrected := part
+			var distance int
+			var probability float64
+			if items := m.lookup(part, Top, maxEditDistance); len(items) > 0 {
+				corrected = items[0].Term
+				distance = items[0].Distance
+				probability = math.Log10(float64(items[0].Count) / total)
+			} else {
+				// unknown words are all edits and are less likely the longer they are
+				distance = i
+				probability = math.Log10(10 / (total * math.Pow(10, float64(i))))
+			}
+
+			c := Segmentation{
+				Segmented:   part,
+				Corrected:   corrected,
+				Distance:    best[j].Distance + distance,
+				Probability: best[j].Probability + probability,
+			}
+			if j > 0 {
+				c.Segmented = best[j].Segmented + " " + part
+				c.Corrected = best[j].Corrected + " " + corrected
+				// the space is an edit
+				c.Distance++
+			}
+
+			if !found[j+i] || c.Distance < best[j+i].Distance ||
+				(c.Distance == best[j+i].Distance && c.Probability > best[j+i].Probability) {
+
+				best[j+i] = c
+				found[j+i] = true
+			}
+		}
+	}
+
+	return best[len(runes)]
+}
@@ -0,0 +1,52 @@
+package spell
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSegment(t *testing.T) {
+	m := NewModel()
+	words := "i\nlove\nyou\njessica\ncorrect\nhorse\nbattery\nstaple\npassword\npass\nword\na\nis\nit\nsit\n"
+	if err := m.LoadWordList(strings.NewReader(words)); err != nil {
+		t.Fatal(err)
+	}
+
+	var segment = []struct {
+		in  string
+		out string
+	}{
+		{"iloveyoujessica", "i love you jessica"},
+		{"correcthorsebatterystaple", "correct horse battery staple"},
+		// a word is better than two
+		{"password", "password"},
+		{"ilove123you", "i love 123 you"},
+		{"i love you", "i love you"},
+		{"", ""},
+	}
+
+	for _, test := range segment {
+		if out := m.Segment(test.in); out != test.out {
+			t.Errorf("%s: should be %q, got %q", test.in, test.out, out)
+		}
+	}
+}
+
+func TestWordSegmentationProbability(t *testing.T) {
+	// both splits are two words the counts pick the more likely one
+	m := NewModel()
+	list := "is 1000\nit 5000\nsit 10\ni 10\nhe 10\nshe 10\n"
+	if err := m.LoadFrequencyList(strings.NewReader(list)); err != nil {
+		t.Fatal(err)
+	}
+
+	if out := m.Segment("itis"); out != "it is" {
+		t.Errorf("should be \"it is\", got %q", out)
+	}
+
+	// corrected with a max edit distance
+	s := m.WordSegmentation("itsi", 1, segmentLength)
+	if s.Segmented != "it si" || s.Corrected != "it is" || s.Distance != 2 {
+		t.Errorf("should be \"it si\" corrected to \"it is\" with a distance of 2, got %+v", s)
+	}
+}
@@ -3,9 +3,15 @@ package spell
 
 import (
 	"bufio"
-	"encoding/gob"
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"sort"
+	"sync"
+
+	"github.com/coolbry95/magicmachine/distance"
 )
 
 // Speller provides a basic interface for spell checking
@@ -22,60 +28,269 @@ type Term struct {
 }
 
 // Model holds the data for the spell checker
+// a Model is safe to use from many goroutines through its methods
 type Model struct {
-	// really should orcrestrate with channels
+	// Data should only be used directly while nothing else uses the Model
 	Data map[string]*Term `json:"data"`
 	// amount of times to see term before adding it
 	Threshold int `json:"threshold"`
-	// edit distance depth
+	// edit distance depth, the most deletes made from a word
+	// lookups further than this can miss words
 	Depth int `json:"depth"`
+	// only the first PrefixLength characters of a word have deletes made
+	// 0 is the whole word
+	PrefixLength int `json:"prefix_length"`
+	// words are normalized before they are added or looked up
+	Normalize Normalization `json:"normalize"`
 	// maximum dictionary term length
 	// dont really know what this is used for
 	// 224 symspell.cs ??
 	Max int `json:"max"`
+	// total count of every word used for how probable a word is
+	Total int `json:"total"`
+
+	// index is a processed model the model was loaded from
+	// Data holds the terms added or changed since and is checked first
+	index *frozenIndex
+
+	// mu guards Data and the counts, lookups share it and adding words takes it
+	mu sync.RWMutex
+}
+
+// Options control how a Model indexes words
+type Options struct {
+	// MaxEditDistance is the most deletes made from a word
+	// it is the furthest a word can be from what is looked up and be found
+	MaxEditDistance int
+	// PrefixLength is how many characters from the start of a word deletes
+	// are made from, it has to be more than MaxEditDistance or 0 for the whole word
+	PrefixLength int
+	// Normalize is applied to words added to the Model and looked up in it
+	Normalize Normalization
+}
+
+// DefaultOptions returns the Options NewModel uses
+// these are the same as symspell with a larger edit distance for mangled passwords
+func DefaultOptions() Options {
+	return Options{
+		MaxEditDistance: defaultEditDistance,
+		PrefixLength:    7,
+	}
 }
 
 // NewModel returns a Model with default parameters
 func NewModel() *Model {
+	return NewModelWithOptions(DefaultOptions())
+}
+
+// NewModelWithOptions returns a Model indexing words with opts
+func NewModelWithOptions(opts Options) *Model {
 	return &Model{
-		Data:      make(map[string]*Term),
-		Threshold: 1,
-		Depth:     100,
-		//depth:     3,
+		Data:         make(map[string]*Term),
+		Threshold:    1,
+		Depth:        opts.MaxEditDistance,
+		PrefixLength: opts.PrefixLength,
+		Normalize:    opts.Normalize,
 		// this is something like max int size i think
 		Max: 10000,
 	}
 }
 
 // LoadWordList loads a wordlist and puts the data into the Model
-func (m *Model) LoadWordList(file io.Reader) {
+// the deletes are made by several goroutines with a Builder
+func (m *Model) LoadWordList(file io.Reader) error {
 	scanner := bufio.NewScanner(file)
 
+	b := NewBuilder()
 	for scanner.Scan() {
-		m.CreateEntry(scanner.Text())
+		b.Add(scanner.Text(), 1)
 	}
 
 	if err := scanner.Err(); err != nil {
-		fmt.Println("err scanning words")
+		return fmt.Errorf("err scanning words: %v", err)
 	}
+
+	b.Build(m)
+	return nil
 }
 
-// SaveWordList saves a wordlist to disc
-func (m *Model) SaveWordList(file io.Writer) {
-	err := gob.NewEncoder(file).Encode(m)
-	if err != nil {
-		fmt.Println(err)
-	}
+// SaveWordList saves a wordlist to disc in the processed format
+func (m *Model) SaveWordList(file io.Writer) error {
+	_, err := m.WriteTo(file)
+	return err
 }
 
 // LoadSavedWordList loads a wordlist that is saved on disc
-func LoadSavedWordList(file io.Reader) *Model {
-	m := NewModel()
-	err := gob.NewDecoder(file).Decode(m)
+// models saved with gob by older versions are still loaded
+// the model is validated before it is returned
+func LoadSavedWordList(file io.Reader) (*Model, error) {
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := newFrozenModel(data, nil)
+	if err == ErrNotModel {
+		m, err = loadGob(bytes.NewReader(data))
+	}
 	if err != nil {
-		fmt.Println(err)
+		return nil, err
+	}
+	return m, nil
+}
+
+// Validate checks that the model is usable
+// every suggestion has to be a word in the model and the model needs words
+func (m *Model) Validate() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.Data == nil {
+		return errors.New("model has no data")
+	}
+	if m.Threshold < 1 {
+		return fmt.Errorf("model threshold %d is less than 1", m.Threshold)
+	}
+	if m.Depth < 1 {
+		return fmt.Errorf("model depth %d is less than 1", m.Depth)
+	}
+	if m.PrefixLength < 0 || (m.PrefixLength > 0 && m.PrefixLength <= m.Depth) {
+		return fmt.Errorf("model prefix length %d has to be 0 or more than the depth %d", m.PrefixLength, m.Depth)
+	}
+	if err := m.Normalize.validate(); err != nil {
+		return fmt.Errorf("model %v", err)
+	}
+
+	if m.index != nil {
+		if err := m.index.validate(); err != nil {
+			return err
+		}
+	}
+
+	words := 0
+	for key, t := range m.Data {
+		if t == nil {
+			return fmt.Errorf("model term %q is empty", key)
+		}
+		if t.Count < 0 {
+			return fmt.Errorf("model term %q has a negative count", key)
+		}
+		if m.isWord(t) {
+			words++
+		}
+		for _, s := range t.Suggestions {
+			if !m.isWordKey(s) {
+				return fmt.Errorf("model term %q suggests %q which is not a word", key, s)
+			}
+		}
+	}
+
+	if words == 0 && (m.index == nil || m.index.wordCount == 0) {
+		return errors.New("model has no words")
+	}
+	return nil
+}
+
+// ModelStats is how big a Model is
+type ModelStats struct {
+	// Words is how many words reached the threshold
+	Words int `json:"words"`
+	// Deletes is how many entries are only deletes or words below the threshold
+	Deletes int `json:"deletes"`
+	// Entries is every word and delete
+	Entries int `json:"entries"`
+	// Suggestions is how many suggestions all of the entries have
+	Suggestions int `json:"suggestions"`
+	// Bytes is about how much memory the entries use
+	// a processed model counts the size of the file
+	Bytes int64 `json:"bytes"`
+}
+
+// sizes used to estimate the memory of an entry in Data
+// a map entry with a string key and a pointer, a Term and a string header for each suggestion
+// suggestions share their bytes with the word
+const (
+	entryBytes      = 16 + 8 + 8
+	termBytes       = 8 + 24
+	suggestionBytes = 16
+)
+
+// Stats counts the entries in the model and estimates their size
+func (m *Model) Stats() ModelStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var stats ModelStats
+	for _, key := range m.keys() {
+		t, _ := m.term(key)
+		// removed terms of a processed model
+		if t.Count == 0 && len(t.Suggestions) == 0 {
+			continue
+		}
+		stats.Entries++
+		if m.isWord(t) {
+			stats.Words++
+		} else {
+			stats.Deletes++
+		}
+		stats.Suggestions += len(t.Suggestions)
+	}
+
+	for key, t := range m.Data {
+		stats.Bytes += int64(entryBytes + len(key) + termBytes + suggestionBytes*cap(t.Suggestions))
+	}
+	if m.index != nil {
+		stats.Bytes += m.index.size()
+	}
+
+	return stats
+}
+
+// term returns the term for key from Data or the processed model
+func (m *Model) term(key string) (*Term, bool) {
+	if t, ok := m.Data[key]; ok {
+		return t, true
+	}
+	if m.index != nil {
+		return m.index.term(key)
+	}
+	return nil, false
+}
+
+// thaw returns the term for key so it can be changed
+// terms from the processed model are copied into Data
+func (m *Model) thaw(key string) (*Term, bool) {
+	if t, ok := m.Data[key]; ok {
+		return t, true
+	}
+	if m.index != nil {
+		if t, ok := m.index.term(key); ok {
+			m.Data[key] = t
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// keys returns every word and delete in the model sorted
+func (m *Model) keys() []string {
+	var keys []string
+	if m.index != nil {
+		keys = m.index.keys()
+	}
+	for key := range m.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// a key can be in both
+	unique := keys[:0]
+	for i, key := range keys {
+		if i == 0 || key != keys[i-1] {
+			unique = append(unique, key)
+		}
 	}
-	return m
+	return unique
 }
 
 // Replace is a wrapper to satisfy the Speller interface
@@ -85,382 +300,343 @@ func (m *Model) Replace(mispelled, correct string) {
 
 // CreateEntry adds an entry to the model
 func (m *Model) CreateEntry(word string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.createEntry(m.Normalize.Normalize(word), 1)
+}
+
+// createEntry adds n to the count of word
+// the deletes of word are indexed when the count reaches the threshold
+func (m *Model) createEntry(word string, n int) {
+	if m.addCount(word, n) {
+		// create suggestions here
+		m.createSuggestions(word)
+	}
+}
+
+// addCount adds n to the count of word
+// it returns true when the count reaches the threshold and the deletes of
+// word need to be indexed
+func (m *Model) addCount(word string, n int) bool {
 	// make this non exported?
-	if v, ok := m.Data[word]; ok {
-		v.Count++
-	} else {
+	v, ok := m.thaw(word)
+	if !ok {
 		// can probably guess the size of suggestions based on length
 		// eg len(word)
-		m.Data[word] = &Term{1, make([]string, 0)}
+		v = &Term{0, make([]string, 0)}
+		m.Data[word] = v
 	}
 
+	previous := v.Count
+	v.Count += n
+	m.Total += n
+
 	// set the max
-	if m.Data[word].Count > m.Max {
-		m.Max = m.Data[word].Count
+	if v.Count > m.Max {
+		m.Max = v.Count
 	}
 
 	// how many times how we seen this term
-	if m.Data[word].Count == m.Threshold {
-		// create suggestions here
-		m.createSuggestions(word)
-	}
+	return previous < m.Threshold && v.Count >= m.Threshold
 }
 
-// this needs to uniq the values returned
+// createSuggestions indexes every delete of word so word is suggested for it
+// a word is only indexed once when it reaches the threshold so the
+// suggestion lists do not need to be checked for word
 func (m *Model) createSuggestions(word string) {
-	// get all of the edits
-	edits := Edits([]rune(word), 0, m.Depth)
-
-	// for each delete add the word it derived from to the suggestions for it
-	for _, val := range edits {
-		// make this 0 instead of one??
-		// because it is an edit not a word
-		m.Data[val] = &Term{1, make([]string, 0)}
-		m.Data[val].Suggestions = append(m.Data[val].Suggestions, word)
+	for _, val := range m.wordDeletes(word) {
+		m.addSuggestion(val, word)
 	}
+}
 
-	//m.Data[word].Suggestions = append(m.Data[word].Suggestions, edits...)
+// wordDeletes returns the keys word is indexed under
+// they are the deletes of the first PrefixLength characters of word up to
+// Depth deletes and the prefix itself when word is longer than it
+// words longer than the prefix are found by their prefix so long words do
+// not make huge numbers of deletes
+func (m *Model) wordDeletes(word string) []string {
+	runes := []rune(word)
+	if m.PrefixLength > 0 && len(runes) > m.PrefixLength {
+		runes = runes[:m.PrefixLength]
+		deletes := Edits(runes, 0, m.Depth)
+		return append(deletes, string(runes))
+	}
+	return Edits(runes, 0, m.Depth)
 }
 
-// with pointer make a method on type so
-// func (deletes *type) Edits(..) {}
-/*
-func Edit(word []rune, editDistance int, depth int, deletes *[]string) *[]string {
-	// increase how far we have gone
-	editDistance++
-	if len(word) > 1 {
-		// create an edit for the length of the word
-		for i := 0; i < len(word); i++ {
-			// append the one letter removed
-			*deletes = append(*deletes, string(word[:i])+string(word[i+1:]))
-			// if we havent hit how many edits we want to do
-			if editDistance < depth {
-				Edits(word[i+1:], editDistance, depth, deletes)
-			}
-		}
+// addSuggestion adds word to the suggestions of a delete of it
+// deletes that are not words have a Count of 0 and only hold suggestions
+func (m *Model) addSuggestion(val, word string) {
+	// keep the count and suggestions already there
+	// the delete can be a word or a delete of another word
+	if term, ok := m.thaw(val); ok {
+		term.Suggestions = append(term.Suggestions, word)
+	} else {
+		m.Data[val] = &Term{0, []string{word}}
 	}
-	return deletes
 }
-*/
 
-// Edits creates a list of all deletes from a word up to editDistance
-func Edits(word []rune, editDistance int, depth int) []string {
+// IsWord checks if word is in the dictionary and not only a delete of another word
+func (m *Model) IsWord(word string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 
-	// this needs refactoring to be more efficient
-	// also is there a way to get rid of stuff?
-	// symspell uses a hashset- a list of unique values
+	return m.isWordKey(m.Normalize.Normalize(word))
+}
+
+func (m *Model) isWordKey(word string) bool {
+	term, ok := m.term(word)
+	return ok && m.isWord(term)
+}
 
-	// this conatains our edits
+// isWord checks if the term has been seen enough to be a word
+func (m *Model) isWord(term *Term) bool {
+	return term.Count > 0 && term.Count >= m.Threshold
+}
+
+// Edits creates a list of all deletes from a word up to maxEditDistance
+// editDistance is how many deletes were already made to get word
+// each delete is only in the list once
+func Edits(word []rune, editDistance int, maxEditDistance int) []string {
 	deletes := make([]string, 0, len(word))
+	edits(word, editDistance, maxEditDistance, NewHash(), &deletes)
+	return deletes
+}
+
+// edits adds the deletes of word that are not in seen to deletes
+// this is symspell's Edits
+// words as short as maxEditDistance are deleted down to the empty string so
+// a word completely different from what is looked up is still found
+func edits(word []rune, editDistance int, maxEditDistance int, seen Hash, deletes *[]string) {
 	// increase how far we have gone
 	editDistance++
-	if len(word) > 1 {
-		// create an edit for the length of the word
-		for i := 0; i < len(word); i++ {
-			// append the one letter removed
-			deletes = append(deletes, string(word[:i])+string(word[i+1:]))
-			// if we havent hit how many edits we want to do
-			if editDistance < depth {
-				// stuff is a temp variable that holds the edits
-				// what if we do &slice??
-				// https://play.golang.org/p/WAEIzRgSNB
-				// mine
-				// https://play.golang.org/p/AuEXks0ZyD
-				//https://play.golang.org/p/MvieVStQst
-				stuff := Edits(word[i+1:], editDistance, depth)
-				// this appends the edits to delete
-				// this is necessary to have the delete outside the scope of this function
-				for _, v := range stuff {
-					deletes = append(deletes, v)
-				}
-			}
-		}
+	if len(word) == 0 {
+		return
 	}
-	return deletes
-}
 
-// Suggest is a wrapper for the Speller interface
-func (m *Model) Suggest(word string) []string {
-	return m.Suggestion(word, 10)
-}
+	delete := make([]rune, len(word)-1)
+	for i := 0; i < len(word); i++ {
+		// the word with one letter removed
+		copy(delete, word[:i])
+		copy(delete[i:], word[i+1:])
 
-// Suggestion checks the spelling of a word
-func (m *Model) Suggestion(word string, editDistanceMax int) []string {
-	// and sort based on likelyness
-	// change suggestions to a *Term will have to change slicing operations
-	wordRune := []rune(word)
-	if len(wordRune)-editDistanceMax > m.Max {
-		return []string{}
-	}
+		// the deletes of a delete already seen have been made too
+		d := string(delete)
+		if !seen.Add(d) {
+			continue
+		}
+		*deletes = append(*deletes, d)
 
-	hashset1 := NewHash()
-	hashset2 := NewHash()
+		// if we havent hit how many edits we want to do
+		if editDistance < maxEditDistance {
+			edits(delete, editDistance, maxEditDistance, seen, deletes)
+		}
+	}
+}
 
-	candidates := []string{}
-	candidates = append(candidates, word)
-	//hashset1.Add(word)
-	suggestions := []string{}
+// defaultEditDistance is the max edit distance words are indexed with by default
+// lookups get a lot slower as the distance grows
+const defaultEditDistance = 3
 
-	//fmt.Println(len(candidates))
+// SuggestDistance is the furthest Suggest looks, the same as the first
+// versions and the default max word distance of rulegen
+// models indexed deeper than this are only searched this far
+const SuggestDistance = 10
 
-	for len(candidates) > 0 {
-		// this does not change the slice the undlerying array is still the same
-		//candidate, candidates := candidates[0], candidates[1:]
-		// can do this https://play.golang.org/p/pyum8Afrin
-		candidate := candidates[0]
-		candidates = append(candidates[:0], candidates[1:]...)
-		candidateRune := []rune(candidate)
+// Suggest is a wrapper for the Speller interface
+// the suggestions closest to the word are returned most frequent first
+// it looks as far as the words were indexed up to SuggestDistance
+func (m *Model) Suggest(word string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 
-		if temp, ok := m.Data[candidate]; ok {
-			if temp.Count > 0 && hashset2.Add(candidate) {
-				//fmt.Println("ok added to suggestions")
-				suggestions = append(suggestions, temp.Suggestions[:]...)
-			}
+	radius := m.Depth
+	if radius > SuggestDistance {
+		radius = SuggestDistance
+	}
+	return terms(m.lookup(m.Normalize.Normalize(word), Closest, radius))
+}
 
-			for _, suggestion := range suggestions {
-				suggestionRune := []rune(suggestion)
+// Suggestion returns every word within editDistanceMax of word
+// ordered by distance and then by how frequent the word is
+func (m *Model) Suggestion(word string, editDistanceMax int) []string {
+	return terms(m.Lookup(word, All, editDistanceMax))
+}
 
-				if hashset2.Add(suggestion) {
+// Verbosity controls which suggestions Lookup returns
+type Verbosity int
 
-					// check the beginning and back of the suggestion
-					prefix := 0
-					suffix := 0
-					for (prefix < len(suggestionRune)) && (prefix < len(wordRune)) &&
-						(suggestionRune[prefix] == wordRune[prefix]) {
+const (
+	// Top is only the closest and most frequent suggestion
+	Top Verbosity = iota
+	// Closest is every suggestion at the smallest distance found
+	Closest
+	// All is every suggestion within the max edit distance
+	All
+)
 
-						prefix++
-					}
-					for (suffix < len(suggestionRune)-prefix) && (suffix < len(wordRune)-prefix) &&
-						(suggestionRune[len(suggestionRune)-suffix-prefix-1] == wordRune[len(wordRune)-suffix-prefix-1]) {
+// SuggestItem is a single suggestion for a word
+type SuggestItem struct {
+	Term     string `json:"term"`
+	Distance int    `json:"distance"`
+	Count    int    `json:"count"`
+}
 
-						suffix++
-					}
+// SuggestItems is sorted by the closest and then most frequent suggestion first
+type SuggestItems []SuggestItem
 
-					distance := 0
-					if suggestion != word {
-						if len(suggestionRune) == len(wordRune) {
-							if (prefix > 0) || (suffix > 0) {
-								distance = len(wordRune) - len(candidateRune)
-							}
-						} else if len(word) == len(candidate) {
-							if (prefix > 0) || (suffix > 0) {
-								distance = len(suggestionRune) - len(candidateRune)
-							}
-						} else {
-							if (prefix > 0) || (suffix > 0) {
-
-								// can used optimized one here from edit.go
-								distance = DamerauLevenshteinDistance(
-									suggestionRune[prefix:len(suggestionRune)-suffix],
-									wordRune[prefix:len(wordRune)-suffix])
-							} else {
-								if (prefix > 0) || (suffix > 0) {
-									distance = DamerauLevenshteinDistance(suggestionRune, wordRune)
-								}
-							}
-						}
-					}
+func (s SuggestItems) Len() int      { return len(s) }
+func (s SuggestItems) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s SuggestItems) Less(i, j int) bool {
+	if s[i].Distance != s[j].Distance {
+		return s[i].Distance < s[j].Distance
+	}
+	if s[i].Count != s[j].Count {
+		return s[i].Count > s[j].Count
+	}
+	return s[i].Term < s[j].Term
+}
 
-					// remove for verbose missing here
+// terms returns the words of the suggestions in order
+func terms(items []SuggestItem) []string {
+	words := make([]string, len(items))
+	for i, item := range items {
+		words[i] = item.Term
+	}
+	return words
+}
 
-					if distance <= editDistanceMax {
-						if val, ok := m.Data[suggestion]; ok {
-							suggestions = append(suggestions, val.Suggestions[:]...)
-						}
-					}
-				}
-			}
-		}
+// Lookup finds the words within maxEditDistance of word
+// the words the deletes of word are deletes of are checked with the real
+// edit distance so the distances are exact
+func (m *Model) Lookup(word string, verbosity Verbosity, maxEditDistance int) []SuggestItem {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 
-		if len(wordRune)-len(candidateRune) < editDistanceMax {
-			// verbose stuff here missing
+	return m.lookup(m.Normalize.Normalize(word), verbosity, maxEditDistance)
+}
 
-			// this does the same thing as Edits()
-			for i := 0; i < len(candidateRune); i++ {
-				delete := string(wordRune[:i]) + string(wordRune[i+1:])
-				if hashset1.Add(delete) {
-					candidates = append(candidates, delete)
-				}
-			}
-		}
+// lookup is Lookup for callers holding the lock
+func (m *Model) lookup(word string, verbosity Verbosity, maxEditDistance int) []SuggestItem {
+	wordRune := []rune(word)
+	if len(wordRune)-maxEditDistance > m.Max {
+		return []SuggestItem{}
 	}
 
-	temp := []string{}
-	a := make(map[string]struct{})
-	for _, val := range suggestions {
-		a[val] = struct{}{}
+	// nothing further than the deletes made can be found
+	if maxEditDistance > m.Depth {
+		maxEditDistance = m.Depth
 	}
 
-	for k := range a {
-		temp = append(temp, k)
-	}
+	items := SuggestItems{}
 
-	return temp
+	// best is the furthest a suggestion can be
+	// it shrinks as closer suggestions are found unless all are wanted
+	best := maxEditDistance
+	found := func(term string, distance, count int) {
+		if verbosity != All && distance < best {
+			best = distance
+		}
+		items = append(items, SuggestItem{term, distance, count})
+	}
 
-}
+	// deletes of the word that have been queued
+	seenCandidates := NewHash()
+	// words that have been checked
+	seenSuggestions := NewHash()
 
-// DamerauLevenshteinDistance returns the DamerauLevenshteinDistance
-func DamerauLevenshteinDistance(a, b []rune) int {
-	if len(a) == 0 {
-		return len(b)
-	} else if len(b) == 0 {
-		return len(a)
+	// the word itself
+	if term, ok := m.term(word); ok && m.isWord(term) {
+		seenSuggestions.Add(word)
+		found(word, 0, term.Count)
 	}
 
-	// make the shorter string a can speed up by just running the inner loop
-	// swap the two
-	if len(a) > len(b) {
-		a, b = b, a
+	// words are indexed by their prefix so the deletes are made from the
+	// prefix of the word
+	prefix := wordRune
+	if m.PrefixLength > 0 && len(prefix) > m.PrefixLength {
+		prefix = prefix[:m.PrefixLength]
 	}
 
-	aLen := len(a)
-	bLen := len(b)
+	// candidates are in order of how many deletes were made from the prefix
+	candidates := []string{string(prefix)}
+	seenCandidates.Add(candidates[0])
 
-	// ignore suffix that are the same
-	for aLen > 0 && a[aLen-1] == b[bLen-1] {
-		aLen--
-		bLen--
-	}
+	for len(candidates) > 0 {
+		candidate := candidates[0]
+		candidates = candidates[1:]
+		candidateRune := []rune(candidate)
 
-	start := 0
-	if a[0] == b[0] || aLen == 0 {
-		// prefix common to both strings can be ignored
-		for start < aLen && a[start] == b[start] {
-			start++
-		}
-		// length of the part excluding common prefix and suffix
-		aLen -= start
-		bLen -= start
-		// if all of shorter string matches prefix and/or suffix of longer string, then
-		// edit distance is just the delete of additional characters present in longer string
-		if aLen == 0 {
-			return bLen
+		// every candidate after this has as many or more deletes
+		deletes := len(prefix) - len(candidateRune)
+		if deletes > best {
+			break
 		}
 
-		// faster than b[start+j] in inner loop below
-		// this is if there is only one char different
-		//if bLen == 1 {
-		//  b = string(b[start])
-		//} else {
-		b = b[start : bLen+start]
-		//}
-	}
-
-	v0 := make([]int, bLen)
-	v2 := make([]int, bLen)
-
-	for j := 0; j < bLen; j++ {
-		v0[j] = j + 1
-	}
-
-	aChar := a[0]
-	current := 0
-	for i := 0; i < aLen; i++ {
-		prevaChar := aChar
-		aChar = a[start+i]
-		bChar := b[0]
-		left := i
-		current = i + 1
-		nextTransCost := 0
-		for j := 0; j < bLen; j++ {
-			above := current
-			thisTransCost := nextTransCost
-			nextTransCost = v2[j]
-			current = left
-			v2[j] = current
-			left = v0[j]
-			prevbChar := bChar
-			bChar = b[j]
-			if aChar != bChar {
-				if left < current {
-					current = left
+		if term, ok := m.term(candidate); ok {
+			// the candidate is a word itself
+			if m.isWord(term) && seenSuggestions.Add(candidate) {
+				// the deletes are only the distance when the whole word was used
+				dist := deletes
+				if len(prefix) != len(wordRune) {
+					dist = distance.OSA(candidateRune, wordRune)
 				}
-				if above < current {
-					current = above
-				}
-				current++
-				if i != 0 && j != 0 && aChar == prevbChar && prevaChar == bChar {
-					thisTransCost++
-					if thisTransCost < current {
-						current = thisTransCost
-					}
+				if dist <= best {
+					found(candidate, dist, term.Count)
 				}
 			}
-			v0[j] = current
-		}
-	}
-	return current
-}
 
-/*
-// doesnt work
-func DamerauLevenshteinDistance(a, b []rune) int {
-	matrix := make([][]int, len(a)+1)
+			// the words the candidate is a delete of
+			for _, suggestion := range term.Suggestions {
+				if !seenSuggestions.Add(suggestion) {
+					continue
+				}
 
-	for i := 0; i < len(a)+1; i++ {
-		matrix[i] = make([]int, len(b)+1)
-		matrix[i][0] = i
-	}
-	for j := 0; j < len(b)+1; j++ {
-		matrix[0][j] = j
-	}
+				suggestionRune := []rune(suggestion)
+				if abs(len(suggestionRune)-len(wordRune)) > best {
+					continue
+				}
 
-	for i := 1; i < len(a)+1; i++ {
-		for j := 1; j < len(b)+1; j++ {
-			if a[i-1] == b[j-1] {
-				matrix[i][j] = matrix[i-1][j-1]
-			} else {
-				insertion := matrix[i-1][j] + 1
-				deletion := matrix[i][j-1] + 1
-				substitution := matrix[i-1][j-1] + 1
+				dist := distance.OSA(suggestionRune, wordRune)
+				if dist <= best {
+					if t, ok := m.term(suggestion); ok {
+						found(suggestion, dist, t.Count)
+					}
+				}
+			}
+		}
 
-				if i > 0 && j > 0 {
-					// index error here
-					transposition := matrix[i-2][j-2] + 1
-					matrix[i][j] =
-						mini(insertion, deletion, substitution, transposition)
-				} else {
-					matrix[i][j] = mini(insertion, deletion, substitution)
+		// delete from the candidate not the word so deletes add up
+		if deletes < best && len(candidateRune) > 0 {
+			for i := 0; i < len(candidateRune); i++ {
+				delete := string(candidateRune[:i]) + string(candidateRune[i+1:])
+				if seenCandidates.Add(delete) {
+					candidates = append(candidates, delete)
 				}
 			}
 		}
 	}
-	return matrix[0][len(matrix[0])-1]
-}
-*/
 
-// mini returns the lowest value of ints
-func mini(a ...int) int {
-	lowest := a[0]
-	for _, val := range a[1:] {
-		if val < lowest {
-			lowest = val
-		}
+	sort.Sort(items)
+
+	// drop the suggestions found before closer ones
+	end := 0
+	for end < len(items) && items[end].Distance <= best {
+		end++
 	}
-	return lowest
-}
-
-// this trains from and io.Reader
-/*
-func trainword(Data io.Reader) map[string]int {
-	NWORDS := make(map[string]int)
-
-	// https://github.com/wolfgarbe/symspell/blob/master/symspell.cs
-	// regex takex from there
-	// note different from Norvigs
-	// that guy is a jack ass that regex makes no sense only matches
-	// ]3 or something like that
-	// \w is only for ASCII
-	// this one is closer to what \w but without '_'
-	//`[\p{L}\p{N}]+`
-	// bufio.scanwords?
-	pattern := regexp.MustCompile(`[\w-[\d_]]+`)
-	scanner := bufio.NewScanner(Data)
-	for scanner.Scan() {
-		w := pattern.FindAllString(strings.ToLower(scanner.Text()), -1)
-		NWORDS[w]++
+	items = items[:end]
+
+	if verbosity == Top && len(items) > 1 {
+		items = items[:1]
 	}
 
-	return NWORDS
+	return items
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
 }
-*/
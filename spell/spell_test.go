@@ -1,45 +1,391 @@
 package spell
 
 import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/coolbry95/magicmachine/distance"
 )
 
 // dont really know how to test this
-func TestNewModel(t *Testing.t) {
+func TestNewModel(t *testing.T) {
 	//model := NewModel()
 
 	/*
-	return &Model{
-		data:      make(map[string]*Term),
-		threshold: 1,
-		depth:     3,
-		max:       0,
-	}
+		return &Model{
+			data:      make(map[string]*Term),
+			threshold: 1,
+			depth:     3,
+			max:       0,
+		}
 	*/
 }
 
-func Edits(t *Testing.t) {
+// bigWordlist makes every four letter word from the letters abcdefg
+// and the same words with an s on the end so many of the words are
+// deletes of other words
+func bigWordlist() []string {
+	letters := "abcdefg"
+	var words []string
+	for _, a := range letters {
+		for _, b := range letters {
+			for _, c := range letters {
+				for _, d := range letters {
+					word := string([]rune{a, b, c, d})
+					words = append(words, word, word+"s")
+				}
+			}
+		}
+	}
+	return words
+}
+
+func TestLoadWordList(t *testing.T) {
+	words := bigWordlist()
+	m := NewModel()
+	if err := m.LoadWordList(strings.NewReader(strings.Join(words, "\n"))); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, word := range words {
+		term, ok := m.Data[word]
+		if !ok {
+			t.Fatalf("%s is missing", word)
+		}
+		// each word is in the list once
+		if term.Count != 1 {
+			t.Errorf("%s: count should be 1, got %d", word, term.Count)
+		}
+		if !m.IsWord(word) {
+			t.Errorf("%s should be a word", word)
+		}
+
+		// every delete of the word should suggest it
+		in := []rune(word)
+		for i := range in {
+			d := string(in[:i]) + string(in[i+1:])
+			term, ok := m.Data[d]
+			if !ok {
+				t.Fatalf("%s: delete %s is missing", word, d)
+			}
+			if !contains(term.Suggestions, word) {
+				t.Errorf("%s: delete %s should suggest it", word, d)
+			}
+		}
+	}
+
+	// the four letter words are also deletes of the five letter words
+	// they still need to suggest the five letter words
+	if term := m.Data["abcd"]; !contains(term.Suggestions, "abcds") {
+		t.Errorf("abcd should suggest abcds, got %v", term.Suggestions)
+	}
+
+	// deletes that are not words
+	if m.IsWord("abc") {
+		t.Errorf("abc is only a delete and should not be a word")
+	}
+	if term := m.Data["abc"]; term.Count != 0 {
+		t.Errorf("abc count should be 0, got %d", term.Count)
+	}
+}
+
+// errReader fails every read
+type errReader struct{}
+
+func (errReader) Read(p []byte) (int, error) { return 0, errors.New("read failed") }
+
+func TestLoadWordListError(t *testing.T) {
+	if err := NewModel().LoadWordList(errReader{}); err == nil {
+		t.Errorf("a failed read should be returned")
+	}
+}
+
+func TestSuggestAfterLoad(t *testing.T) {
+	m := NewModel()
+	m.LoadWordList(strings.NewReader(strings.Join(bigWordlist(), "\n")))
+
+	var suggest = []struct {
+		in  string
+		out string
+	}{
+		// a letter missing
+		{"abd", "abcd"},
+		// the word itself
+		{"gfed", "gfed"},
+		{"gfeds", "gfeds"},
+	}
+
+	for _, test := range suggest {
+		if out := m.Suggest(test.in); !contains(out, test.out) {
+			t.Errorf("%s: should suggest %s, got %v", test.in, test.out, out)
+		}
+	}
+}
+
+func TestSuggestDepth(t *testing.T) {
+	// suggestions are as far as the words were indexed
+	m := NewModelWithOptions(Options{MaxEditDistance: 5})
+	m.LoadWordList(strings.NewReader("password\n"))
+
+	if out := m.Suggest("pass"); strings.Join(out, " ") != "password" {
+		t.Errorf("pass: should suggest password, got %v", out)
+	}
+
+	// models indexed deeper are only searched as far as SuggestDistance
+	m = NewModelWithOptions(Options{MaxEditDistance: 12})
+	m.LoadWordList(strings.NewReader("password1234\n"))
+	if out := m.Suggest("pa"); strings.Join(out, " ") != "password1234" {
+		t.Errorf("pa: should suggest password1234, got %v", out)
+	}
+	if out := m.Suggest("p"); len(out) != 0 {
+		t.Errorf("p: should be further than SuggestDistance, got %v", out)
+	}
+}
+
+func TestCreateEntryKeepsSuggestions(t *testing.T) {
+	m := NewModel()
+	m.CreateEntry("loved")
+	// love is a delete of loved and then becomes a word
+	m.CreateEntry("love")
+	m.CreateEntry("love")
+
+	term := m.Data["love"]
+	if term.Count != 2 {
+		t.Errorf("love count should be 2, got %d", term.Count)
+	}
+	if !contains(term.Suggestions, "loved") {
+		t.Errorf("love should still suggest loved, got %v", term.Suggestions)
+	}
+	// ove is a delete of both
+	term = m.Data["ove"]
+	if !contains(term.Suggestions, "love") || !contains(term.Suggestions, "loved") {
+		t.Errorf("ove should suggest love and loved, got %v", term.Suggestions)
+	}
+	if len(term.Suggestions) != 2 {
+		t.Errorf("ove suggestions should not repeat, got %v", term.Suggestions)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEdits(t *testing.T) {
 
 	var editTest = []struct {
-		in string
+		in  string
+		max int
 		out []string
-	} {
-		{"testing", []string{}},
-		{"big words here", []string{}},
-		{"add more", []string{}},
+	}{
+		{"abc", 1, []string{"bc", "ac", "ab"}},
+		{"abc", 2, []string{"bc", "c", "b", "ac", "a", "ab"}},
+		// each delete is only there once
+		{"aab", 2, []string{"ab", "b", "a", "aa"}},
+		{"a", 2, []string{}},
+	}
+
+	for _, test := range editTest {
+		out := Edits([]rune(test.in), 0, test.max)
+		if strings.Join(out, " ") != strings.Join(test.out, " ") {
+			t.Errorf("%s %d: should be %v, got %v", test.in, test.max, test.out, out)
+		}
+	}
+
+	// every way of deleting up to two letters
+	in := []rune("testing")
+	expected := NewHash()
+	for i := range in {
+		one := string(in[:i]) + string(in[i+1:])
+		expected.Add(one)
+		oneRune := []rune(one)
+		for j := range oneRune {
+			expected.Add(string(oneRune[:j]) + string(oneRune[j+1:]))
+		}
+	}
+	out := Edits(in, 0, 2)
+	if len(out) != expected.Len() {
+		t.Errorf("testing: should have %d deletes, got %d", expected.Len(), len(out))
+	}
+	for _, d := range out {
+		if !expected.Exists(d) {
+			t.Errorf("testing: %s is not a delete", d)
+		}
+	}
+}
+
+func TestPrefixLength(t *testing.T) {
+	words := "international\ninternationally\ninterpretation\nsupercalifragilistic\nsuper\nsupper\n"
+	whole := NewModelWithOptions(Options{MaxEditDistance: 2})
+	whole.LoadWordList(strings.NewReader(words))
+	prefix := NewModelWithOptions(Options{MaxEditDistance: 2, PrefixLength: 5})
+	prefix.LoadWordList(strings.NewReader(words))
+
+	if len(prefix.Data) >= len(whole.Data) {
+		t.Errorf("the prefix should make fewer deletes, got %d and %d", len(prefix.Data), len(whole.Data))
+	}
+
+	// the same words are found either way
+	for _, word := range []string{"internatonal", "intrnationaly", "supercalifragilistik", "supr", "sper", "uper", "interpetation"} {
+		w := whole.Lookup(word, All, 2)
+		p := prefix.Lookup(word, All, 2)
+		if len(w) != len(p) {
+			t.Errorf("%s: should be %v, got %v", word, w, p)
+			continue
+		}
+		for i := range w {
+			if w[i] != p[i] {
+				t.Errorf("%s: should be %v, got %v", word, w, p)
+				break
+			}
+		}
+	}
+
+	m := NewModelWithOptions(Options{MaxEditDistance: 3, PrefixLength: 3})
+	m.LoadWordList(strings.NewReader(words))
+	if err := m.Validate(); err == nil {
+		t.Errorf("a prefix length not more than the max edit distance should fail")
 	}
 }
 
+func TestStats(t *testing.T) {
+	m := NewModelWithOptions(Options{MaxEditDistance: 1})
+	m.LoadWordList(strings.NewReader("love\nlove\nlive\n"))
 
-func (t *Testing.t){
+	stats := m.Stats()
+	// ove loe lov ive lie liv and lve are deletes
+	if stats.Words != 2 || stats.Deletes != 7 || stats.Entries != 9 {
+		t.Errorf("should be 2 words, 7 deletes and 9 entries, got %+v", stats)
+	}
+	// lve suggests both
+	if stats.Suggestions != 8 {
+		t.Errorf("should be 8 suggestions, got %d", stats.Suggestions)
+	}
+	if stats.Bytes <= 0 {
+		t.Errorf("bytes should be more than 0, got %d", stats.Bytes)
+	}
 }
 
-func DamerauLevenshteinDistance(t *Testing.t){
+func TestSuggestion(t *testing.T) {
+	m := NewModel()
+	m.LoadWordList(strings.NewReader("love\nlove\nlove\nlive\nlover\nglove\nglove\nbattery\n"))
+
+	// ordered by distance then count
+	out := m.Suggestion("lve", 2)
+	expected := []string{"love", "live", "glove", "lover"}
+	if strings.Join(out, " ") != strings.Join(expected, " ") {
+		t.Errorf("should be %v, got %v", expected, out)
+	}
 }
 
-func mini(t *Testing.t) {
+func TestLookup(t *testing.T) {
+	m := NewModel()
+	m.LoadWordList(strings.NewReader("love\nlove\nlove\nlive\nlover\nglove\nglove\nbattery\n"))
+
+	var lookup = []struct {
+		in        string
+		verbosity Verbosity
+		max       int
+		out       []SuggestItem
+	}{
+		{"lve", Top, 2, []SuggestItem{{"love", 1, 3}}},
+		{"lve", Closest, 2, []SuggestItem{{"love", 1, 3}, {"live", 1, 1}}},
+		{"lve", All, 2, []SuggestItem{{"love", 1, 3}, {"live", 1, 1}, {"glove", 2, 2}, {"lover", 2, 1}}},
+		{"lve", All, 1, []SuggestItem{{"love", 1, 3}, {"live", 1, 1}}},
+		// the word itself is closest
+		{"love", Closest, 2, []SuggestItem{{"love", 0, 3}}},
+		// a transposition is one edit
+		{"batetry", Top, 2, []SuggestItem{{"battery", 1, 1}}},
+		{"zzzz", All, 2, []SuggestItem{}},
+	}
+
+	for _, test := range lookup {
+		out := m.Lookup(test.in, test.verbosity, test.max)
+		if len(out) != len(test.out) {
+			t.Errorf("%s %d: should be %v, got %v", test.in, test.verbosity, test.out, out)
+			continue
+		}
+		for i := range out {
+			if out[i] != test.out[i] {
+				t.Errorf("%s %d: should be %v, got %v", test.in, test.verbosity, test.out, out)
+				break
+			}
+		}
+	}
 }
 
-func trainword(t *Testing.t) {
+func TestLookupNothingInCommon(t *testing.T) {
+	m := NewModelWithOptions(Options{MaxEditDistance: 2})
+	m.LoadWordList(strings.NewReader("b\nxy\nlove\n"))
+
+	// words sharing no letters with what is looked up are found through
+	// the empty delete
+	var lookup = []struct {
+		in  string
+		max int
+		out []SuggestItem
+	}{
+		{"da", 2, []SuggestItem{{"b", 2, 1}, {"xy", 2, 1}}},
+		{"q", 1, []SuggestItem{{"b", 1, 1}}},
+		{"q", 2, []SuggestItem{{"b", 1, 1}, {"xy", 2, 1}}},
+		{"", 2, []SuggestItem{{"b", 1, 1}, {"xy", 2, 1}}},
+		{"zzz", 2, []SuggestItem{}},
+	}
+
+	for _, model := range []*Model{m, frozen(t, m)} {
+		for _, test := range lookup {
+			out := model.Lookup(test.in, All, test.max)
+			if !reflect.DeepEqual(out, test.out) {
+				t.Errorf("%q %d: should be %v, got %v", test.in, test.max, test.out, out)
+			}
+		}
+	}
 }
 
+func TestLookupEveryWord(t *testing.T) {
+	// short words from a few letters so many are far apart
+	r := rand.New(rand.NewSource(1))
+	word := func() string {
+		w := make([]byte, r.Intn(5))
+		for i := range w {
+			w[i] = "abcd"[r.Intn(4)]
+		}
+		return string(w)
+	}
+
+	var words []string
+	for i := 0; i < 60; i++ {
+		if w := word(); len(w) > 0 {
+			words = append(words, w)
+		}
+	}
+	m := NewModelWithOptions(Options{MaxEditDistance: 3})
+	m.LoadWordList(strings.NewReader(strings.Join(words, "\n")))
+
+	// every word within the distance is found
+	for i := 0; i < 300; i++ {
+		in := word()
+		for max := 0; max <= m.Depth; max++ {
+			expected := make(map[string]int)
+			for _, w := range words {
+				if d := distance.OSA([]rune(w), []rune(in)); d <= max {
+					expected[w] = d
+				}
+			}
+			out := make(map[string]int)
+			for _, item := range m.Lookup(in, All, max) {
+				out[item.Term] = item.Distance
+			}
+			if !reflect.DeepEqual(out, expected) {
+				t.Fatalf("%q %d: should be %v, got %v", in, max, expected, out)
+			}
+		}
+	}
+}
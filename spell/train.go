@@ -0,0 +1,111 @@
+package spell
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxLineLength is the longest line read from a corpus
+// text files can have a whole paragraph or book on one line
+const maxLineLength = 1024 * 1024
+
+// Tokenize splits text into lower case words
+// words are runs of letters and marks so accented and non latin words are kept
+// numbers and punctuation split words except for an apostrophe inside a word
+// this is close to symspell's [\w-[\d_]]+ but for every language
+func Tokenize(text string) []string {
+	var words []string
+
+	runes := []rune(text)
+	start := -1
+	for i, r := range runes {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+
+		// don't and o'neil are one word
+		if start >= 0 && isApostrophe(r) && i+1 < len(runes) && isWordRune(runes[i+1]) {
+			continue
+		}
+
+		if start >= 0 {
+			words = append(words, strings.ToLower(string(runes[start:i])))
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, strings.ToLower(string(runes[start:])))
+	}
+
+	return words
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r)
+}
+
+func isApostrophe(r rune) bool {
+	return r == '\'' || r == '’'
+}
+
+// Train learns the words and how often they are used from free text
+// such as books, chat logs or the content of a site
+func (m *Model) Train(text io.Reader) error {
+	scanner := bufio.NewScanner(text)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+
+	b := NewBuilder()
+	for scanner.Scan() {
+		for _, word := range Tokenize(scanner.Text()) {
+			b.Add(word, 1)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("err scanning text: %v", err)
+	}
+
+	b.Build(m)
+	return nil
+}
+
+// LoadFrequencyList loads a list of words and counts
+// each line is a word and how many times it was seen separated by spaces or a tab
+// like the symspell frequency dictionaries
+func (m *Model) LoadFrequencyList(file io.Reader) error {
+	scanner := bufio.NewScanner(file)
+
+	b := NewBuilder()
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return fmt.Errorf("frequency list line %d: should be a word and a count", lineNumber)
+		}
+
+		count, err := strconv.Atoi(fields[1])
+		if err != nil || count < 1 {
+			return fmt.Errorf("frequency list line %d: %q is not a count", lineNumber, fields[1])
+		}
+
+		b.Add(fields[0], count)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("err scanning frequency list: %v", err)
+	}
+
+	b.Build(m)
+	return nil
+}
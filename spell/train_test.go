@@ -0,0 +1,95 @@
+package spell
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	var tokenize = []struct {
+		in  string
+		out []string
+	}{
+		{"The quick brown fox", []string{"the", "quick", "brown", "fox"}},
+		{"don't stop, O'Neil!", []string{"don't", "stop", "o'neil"}},
+		{"rock'n'roll 'quoted'", []string{"rock'n'roll", "quoted"}},
+		{"abc123def under_score", []string{"abc", "def", "under", "score"}},
+		{"Café crème brûlée", []string{"café", "crème", "brûlée"}},
+		{"Привет мир", []string{"привет", "мир"}},
+		{"   ", nil},
+	}
+
+	for _, test := range tokenize {
+		out := Tokenize(test.in)
+		if strings.Join(out, "|") != strings.Join(test.out, "|") {
+			t.Errorf("%s: should be %v, got %v", test.in, test.out, out)
+		}
+	}
+}
+
+func TestTrain(t *testing.T) {
+	m := NewModel()
+	text := "I love you. You love me.\nWe're a happy family, a happy family!\n"
+	if err := m.Train(strings.NewReader(text)); err != nil {
+		t.Fatal(err)
+	}
+
+	var counts = map[string]int{
+		"i":      1,
+		"love":   2,
+		"you":    2,
+		"me":     1,
+		"we're":  1,
+		"happy":  2,
+		"family": 2,
+	}
+	for word, count := range counts {
+		if term, ok := m.Data[word]; !ok || term.Count != count {
+			t.Errorf("%s: count should be %d, got %v", word, count, term)
+		}
+	}
+}
+
+func TestLoadFrequencyList(t *testing.T) {
+	m := NewModel()
+	list := "the 23135851162\nlove 1000\nlive 5000\n\nlover\t20\n"
+	if err := m.LoadFrequencyList(strings.NewReader(list)); err != nil {
+		t.Fatal(err)
+	}
+
+	if term := m.Data["live"]; term.Count != 5000 {
+		t.Errorf("live count should be 5000, got %d", term.Count)
+	}
+
+	// more frequent words are suggested first
+	out := m.Suggestion("lve", 1)
+	if strings.Join(out, " ") != "live love" {
+		t.Errorf("should be [live love], got %v", out)
+	}
+
+	for _, bad := range []string{"love", "love many", "love -1", "love 1 2"} {
+		if err := NewModel().LoadFrequencyList(strings.NewReader(bad)); err == nil {
+			t.Errorf("%s: should fail", bad)
+		}
+	}
+}
+
+func TestCreateEntryThreshold(t *testing.T) {
+	m := NewModel()
+	m.Threshold = 5
+
+	m.createEntry("love", 3)
+	if _, ok := m.Data["lve"]; m.IsWord("love") || ok {
+		t.Errorf("love should not be a word yet")
+	}
+
+	// crossing the threshold indexes the word once
+	m.createEntry("love", 3)
+	m.createEntry("love", 3)
+	if !m.IsWord("love") {
+		t.Errorf("love should be a word")
+	}
+	if term := m.Data["lve"]; len(term.Suggestions) != 1 {
+		t.Errorf("lve should suggest love once, got %v", term.Suggestions)
+	}
+}
@@ -0,0 +1,105 @@
+package spell
+
+// Add adds n to the count of word
+// the word is indexed when its count reaches the threshold
+func (m *Model) Add(word string, n int) {
+	if n < 1 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.createEntry(m.Normalize.Normalize(word), n)
+}
+
+// Remove takes word out of the model
+// it is no longer suggested but is kept as a delete of other words
+// it returns false when word was not in the model
+func (m *Model) Remove(word string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.remove(m.Normalize.Normalize(word))
+}
+
+func (m *Model) remove(word string) bool {
+	t, ok := m.thaw(word)
+	if !ok || t.Count == 0 {
+		return false
+	}
+
+	// words below the threshold were never indexed
+	indexed := m.isWord(t)
+	m.Total -= t.Count
+	t.Count = 0
+	m.dropEmpty(word, t)
+
+	if indexed {
+		for _, val := range m.wordDeletes(word) {
+			if d, ok := m.thaw(val); ok {
+				d.Suggestions = without(d.Suggestions, word)
+				m.dropEmpty(val, d)
+			}
+		}
+	}
+	return true
+}
+
+// dropEmpty deletes a term from Data that is no longer a word or a delete
+// terms from the processed model are kept empty so they stay hidden
+func (m *Model) dropEmpty(key string, t *Term) {
+	if t.Count > 0 || len(t.Suggestions) > 0 {
+		return
+	}
+	if m.index != nil {
+		if _, ok := m.index.term(key); ok {
+			return
+		}
+	}
+	delete(m.Data, key)
+}
+
+// without returns list without s
+func without(list []string, s string) []string {
+	out := list[:0]
+	for _, v := range list {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Prune removes every word seen less than min times
+// it returns how many words were removed
+func (m *Model) Prune(min int) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	removed := 0
+	for _, key := range m.keys() {
+		// removing a word can drop deletes still to come
+		if t, ok := m.term(key); ok && t.Count > 0 && t.Count < min && m.remove(key) {
+			removed++
+		}
+	}
+	return removed
+}
+
+// Merge adds the words and counts of other to the model
+// the words are indexed with the options of the model so models
+// built with different options can be merged
+func (m *Model) Merge(other *Model) {
+	b := NewBuilder()
+
+	other.mu.RLock()
+	for _, key := range other.keys() {
+		if t, _ := other.term(key); t.Count > 0 {
+			b.Add(key, t.Count)
+		}
+	}
+	other.mu.RUnlock()
+
+	b.Build(m)
+}
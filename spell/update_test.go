@@ -0,0 +1,123 @@
+package spell
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// load makes a model from a frequency list
+func load(t *testing.T, words string) *Model {
+	t.Helper()
+
+	m := NewModel()
+	if err := m.LoadFrequencyList(strings.NewReader(words)); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// frozen saves and loads m so it is backed by the processed format
+func frozen(t *testing.T, m *Model) *Model {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := m.SaveWordList(&buf); err != nil {
+		t.Fatal(err)
+	}
+	f, err := ReadModel(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestMerge(t *testing.T) {
+	english := "love 3\nlive 1\nbattery 2\n"
+	names := "jessica 4\nlove 1\n"
+	expected := load(t, "love 4\nlive 1\nbattery 2\njessica 4\n")
+
+	m := load(t, english)
+	m.Merge(load(t, names))
+	checkSameModel(t, expected, m)
+
+	// merging processed models
+	m = frozen(t, load(t, english))
+	m.Merge(frozen(t, load(t, names)))
+	checkSameModel(t, expected, m)
+	if err := m.Validate(); err != nil {
+		t.Error(err)
+	}
+	checkSameModel(t, expected, frozen(t, m))
+
+	// the other model's options are not used
+	other := NewModelWithOptions(Options{MaxEditDistance: 1})
+	other.LoadFrequencyList(strings.NewReader(names))
+	m = load(t, english)
+	m.Merge(other)
+	checkSameModel(t, expected, m)
+}
+
+func TestRemove(t *testing.T) {
+	m := load(t, "love 3\nlove 1\nlive 1\nbattery 2\n")
+	if !m.Remove("love") {
+		t.Errorf("love should be removed")
+	}
+	if m.Remove("love") || m.Remove("lve") {
+		t.Errorf("only words can be removed")
+	}
+	expected := load(t, "live 1\nbattery 2\n")
+	checkSameModel(t, expected, m)
+
+	// love is still a delete of loved
+	m = load(t, "love 1\nloved 1\n")
+	m.Remove("love")
+	checkSameModel(t, load(t, "loved 1\n"), m)
+	if m.IsWord("love") {
+		t.Errorf("love should not be a word")
+	}
+	if out := m.Suggest("love"); !contains(out, "loved") {
+		t.Errorf("love should suggest loved, got %v", out)
+	}
+
+	// removing from a processed model hides the terms in it
+	m = frozen(t, load(t, "love 3\nlive 1\nbattery 2\n"))
+	m.Remove("love")
+	checkSameModel(t, expected, m)
+	if out := m.Suggest("lve"); strings.Join(out, " ") != "live" {
+		t.Errorf("lve should only suggest live, got %v", out)
+	}
+	checkSameModel(t, expected, frozen(t, m))
+}
+
+func TestAdd(t *testing.T) {
+	m := frozen(t, load(t, "love 3\nlive 1\n"))
+	m.Add("glove", 2)
+	m.Add("live", 1)
+	m.Add("nothing", 0)
+	checkSameModel(t, load(t, "love 3\nlive 2\nglove 2\n"), m)
+}
+
+func TestPrune(t *testing.T) {
+	words := "love 3\nlive 1\nbattery 2\nlover 1\n"
+	expected := load(t, "love 3\nbattery 2\n")
+
+	m := load(t, words)
+	if n := m.Prune(2); n != 2 {
+		t.Errorf("should remove 2 words, got %d", n)
+	}
+	checkSameModel(t, expected, m)
+
+	m = frozen(t, load(t, words))
+	m.Prune(2)
+	checkSameModel(t, expected, m)
+
+	// words below the threshold are pruned too
+	m = NewModel()
+	m.Threshold = 2
+	m.LoadFrequencyList(strings.NewReader(words))
+	m.Prune(2)
+	if stats := m.Stats(); stats.Words != 2 || m.Total != 5 {
+		t.Errorf("should have 2 words and a total of 5, got %+v %d", stats, m.Total)
+	}
+}
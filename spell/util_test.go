@@ -3,8 +3,17 @@ package spell
 import (
 	"testing"
 )
+
 // change name conflicts with sha1
-func NewHash(t *Testing.t) *Hash {
+func TestNewHash(t *testing.T) {
+	h := NewHash()
+	if !h.Add("love") || h.Add("love") {
+		t.Errorf("love should only be added once")
+	}
+	if !h.Exists("love") || h.Exists("live") || h.Len() != 1 {
+		t.Errorf("should only have love, got %v", h)
+	}
+	if !h.Remove("love") || h.Remove("love") || h.Len() != 0 {
+		t.Errorf("love should only be removed once, got %v", h)
+	}
 }
-
-
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/coolbry95/magicmachine/rulegen"
+)
+
+// counter keeps track of how many passwords produced a rule or word
+type counter map[string]int
+
+// count is a single value and how many times it was seen
+type count struct {
+	value string
+	count int
+}
+
+// counts is sorted by the most seen value first
+type counts []count
+
+func (c counts) Len() int      { return len(c) }
+func (c counts) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+func (c counts) Less(i, j int) bool {
+	if c[i].count == c[j].count {
+		return c[i].value < c[j].value
+	}
+	return c[i].count > c[j].count
+}
+
+// total returns the sum of all of the counts
+func (c counter) total() int {
+	t := 0
+	for _, n := range c {
+		t += n
+	}
+	return t
+}
+
+// sorted returns the values seen at least min times ordered by frequency
+func (c counter) sorted(min int) counts {
+	sorted := make(counts, 0, len(c))
+	for value, n := range c {
+		if n >= min {
+			sorted = append(sorted, count{value, n})
+		}
+	}
+	sort.Sort(sorted)
+	return sorted
+}
+
+// writeCounts writes each value on its own line, most frequent first
+func writeCounts(fileName string, c counts) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	buf := bufio.NewWriter(file)
+	for _, v := range c {
+		fmt.Fprintln(buf, v.value)
+	}
+	return buf.Flush()
+}
+
+// statistics is the aggregate of a whole analysis run
+type statistics struct {
+	passwords int
+	minCount  int
+	words     counter
+	rules     counter
+}
+
+func newStatistics(minCount int) *statistics {
+	return &statistics{
+		minCount: minCount,
+		words:    make(counter),
+		rules:    make(counter),
+	}
+}
+
+// add counts the words and rules generated for a single password
+// each word and rule is only counted once per password
+func (s *statistics) add(words []rulegen.Word) {
+	s.passwords++
+
+	seenWords := make(map[string]struct{})
+	seenRules := make(map[string]struct{})
+	for _, word := range words {
+		suggestion := encodeHex(word.Suggestion)
+		if _, ok := seenWords[suggestion]; !ok {
+			seenWords[suggestion] = struct{}{}
+			s.words[suggestion]++
+		}
+		for _, r := range word.Rules {
+			line := rulegen.RuleLine(r)
+			if _, ok := seenRules[line]; !ok {
+				seenRules[line] = struct{}{}
+				s.rules[line]++
+			}
+		}
+	}
+}
+
+// write saves the sorted word and rule files along with a summary
+func (s *statistics) write(basename string) error {
+	sortedWords := s.words.sorted(s.minCount)
+	sortedRules := s.rules.sorted(s.minCount)
+
+	if err := writeCounts(basename+"-sorted.word", sortedWords); err != nil {
+		return err
+	}
+	if err := writeCounts(basename+"-sorted.rule", sortedRules); err != nil {
+		return err
+	}
+
+	file, err := os.Create(basename + ".stats")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	buf := bufio.NewWriter(file)
+	fmt.Fprintf(buf, "passwords analyzed: %d\n", s.passwords)
+	fmt.Fprintf(buf, "minimum count: %d\n", s.minCount)
+	fmt.Fprintf(buf, "words generated: %d\n", s.words.total())
+	fmt.Fprintf(buf, "unique words: %d\n", len(s.words))
+	fmt.Fprintf(buf, "words written: %d\n", len(sortedWords))
+	fmt.Fprintf(buf, "rules generated: %d\n", s.rules.total())
+	fmt.Fprintf(buf, "unique rules: %d\n", len(s.rules))
+	fmt.Fprintf(buf, "rules written: %d\n", len(sortedRules))
+
+	writeTop(buf, "top words", sortedWords)
+	writeTop(buf, "top rules", sortedRules)
+
+	return buf.Flush()
+}
+
+// how many of the most frequent values to show in the summary
+const topCount = 25
+
+func writeTop(buf *bufio.Writer, title string, c counts) {
+	fmt.Fprintf(buf, "\n%s:\n", title)
+	for i, v := range c {
+		if i >= topCount {
+			break
+		}
+		fmt.Fprintf(buf, "%8d  %s\n", v.count, v.value)
+	}
+}
+
+const statsDescription = "counts the lines of rule or word files and prints them most frequent first"
+
+func runStats(args []string) int {
+	flags := newFlagSet("stats", "files...", statsDescription)
+	minimum := flags.Int("mincount", 1, "minimum times a line must be seen to be printed")
+	showCounts := flags.Bool("counts", false, "print how many times each line was seen")
+
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return flagExit(err)
+	}
+
+	if len(args) == 0 {
+		log.Println("no files specified")
+		flags.Usage()
+		return exitUsage
+	}
+
+	c := make(counter)
+	for _, fileName := range args {
+		if err := c.addLines(fileName); err != nil {
+			log.Println(err)
+			return exitError
+		}
+	}
+
+	buf := bufio.NewWriter(os.Stdout)
+	for _, v := range c.sorted(*minimum) {
+		if *showCounts {
+			fmt.Fprintf(buf, "%d\t%s\n", v.count, v.value)
+		} else {
+			fmt.Fprintln(buf, v.value)
+		}
+	}
+	if err := buf.Flush(); err != nil {
+		log.Println(err)
+		return exitError
+	}
+
+	return exitOK
+}
+
+// addLines counts every line in a file
+func (c counter) addLines(fileName string) error {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// rule files have a space after the last rule
+		line := strings.TrimSuffix(scanner.Text(), " ")
+		if len(line) > 0 {
+			c[line]++
+		}
+	}
+
+	return scanner.Err()
+}
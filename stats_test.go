@@ -0,0 +1,54 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coolbry95/magicmachine/rulegen"
+)
+
+func TestStatistics(t *testing.T) {
+	s := newStatistics(2)
+
+	// the same word and rule from one password are only counted once
+	s.add([]rulegen.Word{
+		{Suggestion: "password", Rules: rulegen.Rules{{"$1"}, {"$1"}}},
+		{Suggestion: "password", Rules: rulegen.Rules{{"c"}}},
+	})
+	s.add([]rulegen.Word{
+		{Suggestion: "password", Rules: rulegen.Rules{{"$1"}}},
+		{Suggestion: "monkey", Rules: rulegen.Rules{{"c"}}},
+	})
+	s.add([]rulegen.Word{
+		{Suggestion: "dragon", Rules: rulegen.Rules{{"$!"}}},
+	})
+
+	if s.passwords != 3 {
+		t.Errorf("should be 3 passwords, got %d", s.passwords)
+	}
+
+	var tests = []struct {
+		counter counter
+		min     int
+		out     counts
+	}{
+		{s.words, 1, counts{{"password", 2}, {"dragon", 1}, {"monkey", 1}}},
+		// ties are sorted by value
+		{s.rules, 1, counts{{"$1", 2}, {"c", 2}, {"$!", 1}}},
+		// values seen less than the minimum are left out
+		{s.words, s.minCount, counts{{"password", 2}}},
+		{s.rules, s.minCount, counts{{"$1", 2}, {"c", 2}}},
+		{s.rules, 3, counts{}},
+	}
+
+	for _, test := range tests {
+		out := test.counter.sorted(test.min)
+		if !reflect.DeepEqual(out, test.out) {
+			t.Errorf("%d: should be %v, got %v", test.min, test.out, out)
+		}
+	}
+
+	if total := s.rules.total(); total != 5 {
+		t.Errorf("should be 5 rules, got %d", total)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/coolbry95/magicmachine/spell"
+)
+
+const updateModelDescription = "merges, adds words to, removes words from or prunes a processed dictionary"
+
+// listFlag is a flag that can be given more than once
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+func runUpdateModel(args []string) int {
+	flags := newFlagSet("update-model", "dictionary.processed", updateModelDescription)
+	out := flags.String("out", "", "where to save the processed dictionary (default the dictionary being updated)")
+	var merge, add, remove listFlag
+	flags.Var(&merge, "merge", "processed dictionary to merge in, can be given more than once")
+	flags.Var(&add, "add", "dictionary to add the words of, can be given more than once")
+	flags.Var(&remove, "remove", "wordlist of words to remove, can be given more than once")
+	format := flags.String("format", dictWordlist, "format of the -add dictionaries \"wordlist\", \"text\" or \"frequency\"")
+	prune := flags.Int("prune", 0, "remove words seen less than this many times")
+
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return flagExit(err)
+	}
+
+	if len(args) != 1 {
+		log.Println("specify one processed dictionary to update")
+		flags.Usage()
+		return exitUsage
+	}
+	if err := checkDictFormat(*format); err != nil {
+		log.Println(err)
+		flags.Usage()
+		return exitUsage
+	}
+	if *prune < 0 {
+		log.Println("prune must be at least 0")
+		flags.Usage()
+		return exitUsage
+	}
+	if len(*out) == 0 {
+		*out = args[0]
+	}
+
+	m, err := loadProcessed(args[0])
+	if err != nil {
+		log.Println(err)
+		return exitError
+	}
+	defer m.Close()
+
+	if err := updateModel(m, merge, add, remove, *format, *prune); err != nil {
+		log.Println(err)
+		return exitError
+	}
+	if err := m.Validate(); err != nil {
+		log.Println(err)
+		return exitError
+	}
+	logModelSize(m)
+
+	if err := saveModel(m, *out); err != nil {
+		log.Println(err)
+		return exitError
+	}
+	return exitOK
+}
+
+// updateModel merges, adds, removes and then prunes in that order
+func updateModel(m *spell.Model, merge, add, remove []string, format string, prune int) error {
+	for _, name := range merge {
+		other, err := loadProcessed(name)
+		if err != nil {
+			return err
+		}
+		m.Merge(other)
+		other.Close()
+	}
+
+	for _, name := range add {
+		if err := addDict(m, name, format); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range remove {
+		n, err := removeWords(m, name)
+		if err != nil {
+			return err
+		}
+		log.Printf("removed %d words from %s\n", n, name)
+	}
+
+	if prune > 0 {
+		log.Printf("pruned %d words\n", m.Prune(prune))
+	}
+	return nil
+}
+
+// removeWords removes every word in a wordlist from the model
+// and returns how many were in it
+func removeWords(m *spell.Model, name string) (int, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	n := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if m.Remove(scanner.Text()) {
+			n++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return n, fmt.Errorf("%s: %v", name, err)
+	}
+	return n, nil
+}
+
+// saveModel saves the model in the processed format
+// it is written to a temporary file first so a model can be saved over the
+// file it was opened from while it is still mapped
+func saveModel(m *spell.Model, name string) error {
+	saved, err := ioutil.TempFile(filepath.Dir(name), filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+
+	// temporary files can only be read by the owner
+	if err := saved.Chmod(0644); err != nil {
+		saved.Close()
+		os.Remove(saved.Name())
+		return err
+	}
+	if err := m.SaveWordList(saved); err != nil {
+		saved.Close()
+		os.Remove(saved.Name())
+		return err
+	}
+	if err := saved.Close(); err != nil {
+		os.Remove(saved.Name())
+		return err
+	}
+	return os.Rename(saved.Name(), name)
+}